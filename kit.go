@@ -106,24 +106,210 @@ var Try = utils.Try
 // Version imported
 var Version = utils.Version
 
+// AcceptEncoding imported
+var AcceptEncoding = http.AcceptEncoding
+
+// BasicAuth imported
+var BasicAuth = http.BasicAuth
+
+// BearerAuth imported
+var BearerAuth = http.BearerAuth
+
+// Cassette imported
+type Cassette = http.Cassette
+
+// CassetteAuto imported
+var CassetteAuto = http.CassetteAuto
+
+// CassetteMode imported
+type CassetteMode = http.CassetteMode
+
+// CassetteRecord imported
+var CassetteRecord = http.CassetteRecord
+
+// CassetteRedacted imported
+var CassetteRedacted = http.CassetteRedacted
+
+// CassetteReplay imported
+var CassetteReplay = http.CassetteReplay
+
+// CookieJar imported
+type CookieJar = http.CookieJar
+
+// DefaultBuckets imported
+var DefaultBuckets = http.DefaultBuckets
+
+// DefaultMiddlewares imported
+var DefaultMiddlewares = http.DefaultMiddlewares
+
+// ErrCassetteNoMatch imported
+var ErrCassetteNoMatch = http.ErrCassetteNoMatch
+
+// ErrCurlUnclosedQuote imported
+var ErrCurlUnclosedQuote = http.ErrCurlUnclosedQuote
+
+// ErrInvalidCA imported
+var ErrInvalidCA = http.ErrInvalidCA
+
+// ErrNoUpstream imported
+var ErrNoUpstream = http.ErrNoUpstream
+
+// ErrNotTransport imported
+var ErrNotTransport = http.ErrNotTransport
+
+// ErrWgetMultipart imported
+var ErrWgetMultipart = http.ErrWgetMultipart
+
+// FromCurl imported
+var FromCurl = http.FromCurl
+
+// GenerateCert imported
+var GenerateCert = http.GenerateCert
+
 // GinContext imported
 type GinContext = http.GinContext
 
+// HAR imported
+type HAR = http.HAR
+
+// HARContent imported
+type HARContent = http.HARContent
+
+// HARCreator imported
+type HARCreator = http.HARCreator
+
+// HAREntry imported
+type HAREntry = http.HAREntry
+
+// HARLog imported
+type HARLog = http.HARLog
+
+// HARNameValue imported
+type HARNameValue = http.HARNameValue
+
+// HARPostData imported
+type HARPostData = http.HARPostData
+
+// HARRequest imported
+type HARRequest = http.HARRequest
+
+// HARResponse imported
+type HARResponse = http.HARResponse
+
+// HARTimings imported
+type HARTimings = http.HARTimings
+
+// Limiter imported
+type Limiter = http.Limiter
+
+// Logger imported
+var Logger = http.Logger
+
+// Middleware imported
+type Middleware = http.Middleware
+
+// Mock imported
+type Mock = http.Mock
+
+// MockRequest imported
+type MockRequest = http.MockRequest
+
+// MockServer imported
+var MockServer = http.MockServer
+
+// MockServerContext imported
+type MockServerContext = http.MockServerContext
+
+// MustCassette imported
+var MustCassette = http.MustCassette
+
+// MustCookieJar imported
+var MustCookieJar = http.MustCookieJar
+
+// MustFromCurl imported
+var MustFromCurl = http.MustFromCurl
+
+// MustMockServer imported
+var MustMockServer = http.MustMockServer
+
 // MustServer imported
 var MustServer = http.MustServer
 
+// NewCassette imported
+var NewCassette = http.NewCassette
+
+// NewCookieJar imported
+var NewCookieJar = http.NewCookieJar
+
+// NewHAR imported
+var NewHAR = http.NewHAR
+
+// NewLimiter imported
+var NewLimiter = http.NewLimiter
+
+// OAuth2 imported
+var OAuth2 = http.OAuth2
+
+// OAuth2Context imported
+type OAuth2Context = http.OAuth2Context
+
+// OAuth2Error imported
+type OAuth2Error = http.OAuth2Error
+
+// PaginateContext imported
+type PaginateContext = http.PaginateContext
+
+// ProxyOptions imported
+type ProxyOptions = http.ProxyOptions
+
 // Req imported
 var Req = http.Req
 
 // ReqContext imported
 type ReqContext = http.ReqContext
 
+// RequestID imported
+var RequestID = http.RequestID
+
+// RequestIDKey imported
+var RequestIDKey = http.RequestIDKey
+
+// RetryOnFailure imported
+var RetryOnFailure = http.RetryOnFailure
+
+// RoundTrip imported
+type RoundTrip = http.RoundTrip
+
+// SSEEvent imported
+type SSEEvent = http.SSEEvent
+
 // Server imported
 var Server = http.Server
 
 // ServerContext imported
 type ServerContext = http.ServerContext
 
+// Session imported
+var Session = http.Session
+
+// SessionContext imported
+type SessionContext = http.SessionContext
+
+// StaticOptions imported
+type StaticOptions = http.StaticOptions
+
+// StatusError imported
+type StatusError = http.StatusError
+
+// Timing imported
+type Timing = http.Timing
+
+// Token imported
+type Token = http.Token
+
+// WebSocket imported
+type WebSocket = http.WebSocket
+
 // CD imported
 var CD = os.CD
 
@@ -196,6 +382,9 @@ var SendSigInt = os.SendSigInt
 // WaitSignal imported
 var WaitSignal = os.WaitSignal
 
+// WaitSignalContext imported
+var WaitSignalContext = os.WaitSignalContext
+
 // Walk imported
 var Walk = os.Walk
 
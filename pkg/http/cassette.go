@@ -0,0 +1,276 @@
+package http
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	gos "github.com/ysmood/kit/pkg/os"
+	"github.com/ysmood/kit/pkg/utils"
+)
+
+// CassetteMode decides whether the cassette records or replays
+type CassetteMode int
+
+const (
+	// CassetteAuto records if the cassette file doesn't exist, else replays
+	CassetteAuto CassetteMode = iota
+
+	// CassetteRecord sends the requests and records them
+	CassetteRecord
+
+	// CassetteReplay replays the recorded responses without sending any request
+	CassetteReplay
+)
+
+// CassetteRedacted replaces the values of the redacted headers in the cassette file
+const CassetteRedacted = "[REDACTED]"
+
+// ErrCassetteNoMatch is returned when no recorded interaction matches the request in replay mode
+var ErrCassetteNoMatch = errors.New("no recorded interaction matches the request")
+
+// Cassette records the request and response pairs to a json file, then replays them deterministically.
+// It implements the http.RoundTripper interface, use it via ReqContext.Client(cassette.Client()).
+// The requests are matched by the method, url, body and the headers set by MatchHeaders.
+type Cassette struct {
+	lock sync.Mutex
+
+	path         string
+	record       bool
+	transport    http.RoundTripper
+	matchHeaders []string
+	redact       []string
+	filter       func(req, res http.Header)
+
+	interactions []*cassetteInteraction
+	used         []bool
+}
+
+type cassetteInteraction struct {
+	Request  cassetteRequest  `json:"request"`
+	Response cassetteResponse `json:"response"`
+}
+
+type cassetteRequest struct {
+	Method string       `json:"method"`
+	URL    string       `json:"url"`
+	Header http.Header  `json:"header"`
+	Body   cassetteBody `json:"body"`
+}
+
+type cassetteResponse struct {
+	StatusCode int          `json:"statusCode"`
+	Header     http.Header  `json:"header"`
+	Body       cassetteBody `json:"body"`
+}
+
+// cassetteBody keeps the text readable in the json file and encodes the binary as base64
+type cassetteBody struct {
+	Text   string `json:"text,omitempty"`
+	Base64 string `json:"base64,omitempty"`
+}
+
+func newCassetteBody(b []byte) cassetteBody {
+	if utf8.Valid(b) {
+		return cassetteBody{Text: string(b)}
+	}
+	return cassetteBody{Base64: base64.StdEncoding.EncodeToString(b)}
+}
+
+func (b cassetteBody) bytes() []byte {
+	if b.Base64 != "" {
+		data, _ := base64.StdEncoding.DecodeString(b.Base64)
+		return data
+	}
+	return []byte(b.Text)
+}
+
+// NewCassette creates a cassette of the json file path, in replay mode the file will be loaded
+func NewCassette(path string, mode CassetteMode) (*Cassette, error) {
+	if mode == CassetteAuto {
+		mode = CassetteReplay
+		if !gos.FileExists(path) {
+			mode = CassetteRecord
+		}
+	}
+
+	c := &Cassette{
+		path:      path,
+		record:    mode == CassetteRecord,
+		transport: http.DefaultTransport,
+		redact:    []string{"Authorization", "Proxy-Authorization", "Cookie"},
+	}
+
+	if !c.record {
+		err := gos.ReadJSON(path, &c.interactions)
+		if err != nil {
+			return nil, err
+		}
+		c.used = make([]bool, len(c.interactions))
+	}
+
+	return c, nil
+}
+
+// MustCassette panic version of NewCassette
+func MustCassette(path string, mode CassetteMode) *Cassette {
+	return utils.E(NewCassette(path, mode))[0].(*Cassette)
+}
+
+// MatchHeaders sets the request headers that should also be matched when replaying
+func (c *Cassette) MatchHeaders(keys ...string) *Cassette {
+	c.matchHeaders = keys
+	return c
+}
+
+// RedactHeaders sets the request headers whose values will be replaced with CassetteRedacted in the file,
+// default is Authorization, Proxy-Authorization and Cookie. The redacted headers can't be matched by MatchHeaders.
+func (c *Cassette) RedactHeaders(keys ...string) *Cassette {
+	c.redact = keys
+	return c
+}
+
+// Filter sets the function to modify the copies of the request and response headers before they are recorded,
+// such as to remove the other secrets
+func (c *Cassette) Filter(fn func(req, res http.Header)) *Cassette {
+	c.filter = fn
+	return c
+}
+
+// Transport sets the transport to send the requests in record mode, default is http.DefaultTransport
+func (c *Cassette) Transport(t http.RoundTripper) *Cassette {
+	c.transport = t
+	return c
+}
+
+// Client creates a http client that uses the cassette
+func (c *Cassette) Client() *http.Client {
+	client := newClient()
+	client.Transport = c
+	return client
+}
+
+// RoundTrip implements the http.RoundTripper interface
+func (c *Cassette) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = readBody(req.Body)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if c.record {
+		return c.recordRoundTrip(req, body)
+	}
+
+	return c.replay(req, body)
+}
+
+func (c *Cassette) recordRoundTrip(req *http.Request, body []byte) (*http.Response, error) {
+	r := req.Clone(req.Context())
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	res, err := c.transport.RoundTrip(r)
+	if err != nil {
+		return nil, err
+	}
+
+	resBody, err := readBody(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(resBody))
+
+	reqHeader, resHeader := req.Header.Clone(), res.Header.Clone()
+	for k := range reqHeader {
+		for _, key := range c.redact {
+			if strings.EqualFold(k, key) {
+				reqHeader[k] = []string{CassetteRedacted}
+			}
+		}
+	}
+	if c.filter != nil {
+		c.filter(reqHeader, resHeader)
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.interactions = append(c.interactions, &cassetteInteraction{
+		Request: cassetteRequest{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Header: reqHeader,
+			Body:   newCassetteBody(body),
+		},
+		Response: cassetteResponse{
+			StatusCode: res.StatusCode,
+			Header:     resHeader,
+			Body:       newCassetteBody(resBody),
+		},
+	})
+
+	return res, gos.OutputFile(c.path, c.interactions, nil)
+}
+
+func (c *Cassette) replay(req *http.Request, body []byte) (*http.Response, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for i, it := range c.interactions {
+		if c.used[i] || !c.match(it.Request, req, body) {
+			continue
+		}
+		c.used[i] = true
+
+		resBody := it.Response.Body.bytes()
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", it.Response.StatusCode, http.StatusText(it.Response.StatusCode)),
+			StatusCode:    it.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        it.Response.Header.Clone(), // don't let the caller modify the cassette
+			Body:          ioutil.NopCloser(bytes.NewReader(resBody)),
+			ContentLength: int64(len(resBody)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s %s", ErrCassetteNoMatch, req.Method, req.URL.String())
+}
+
+func (c *Cassette) match(recorded cassetteRequest, req *http.Request, body []byte) bool {
+	if recorded.Method != req.Method || recorded.URL != req.URL.String() ||
+		!bytes.Equal(recorded.Body.bytes(), body) {
+		return false
+	}
+
+	for _, k := range c.matchHeaders {
+		if fmt.Sprint(headerValues(recorded.Header, k)) != fmt.Sprint(headerValues(req.Header, k)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// headerValues gets the values of the key case-insensitively, because the keys set by
+// ReqContext.Header are not canonicalized
+func headerValues(h http.Header, key string) []string {
+	list := []string{}
+	for k, vs := range h {
+		if strings.EqualFold(k, key) {
+			list = append(list, vs...)
+		}
+	}
+	return list
+}
@@ -0,0 +1,84 @@
+package http_test
+
+import (
+	"errors"
+	"net/http"
+	"path/filepath"
+
+	"github.com/ysmood/kit"
+)
+
+func (s *RequestSuite) TestCassette() {
+	path, url := s.path()
+
+	count := 0
+	s.router.POST(path, func(c kit.GinContext) {
+		count++
+		data, _ := c.GetRawData()
+		if string(data) == "bin" {
+			c.Data(200, "", []byte{0xff, 0xfe})
+			return
+		}
+		c.String(200, string(data)+c.GetHeader("a"))
+	})
+
+	p := filepath.Join("tmp", kit.RandString(8), "cassette.json")
+	defer func() { _ = kit.Remove("tmp") }()
+
+	record := kit.MustCassette(p, kit.CassetteAuto)
+	s.Equal("x1", kit.Req(url).Post().StringBody("x").Header("a", "1").Client(record.Client()).MustString())
+	s.Equal("x2", kit.Req(url).Post().StringBody("x").Header("a", "2").Client(record.Client()).MustString())
+	s.Equal([]byte{0xff, 0xfe}, kit.Req(url).Post().StringBody("bin").Client(record.Client()).MustBytes())
+	s.Equal(3, count)
+
+	replay := kit.MustCassette(p, kit.CassetteAuto).MatchHeaders("a")
+	s.Equal("x2", kit.Req(url).Post().StringBody("x").Header("a", "2").Client(replay.Client()).MustString())
+	s.Equal("x1", kit.Req(url).Post().StringBody("x").Header("a", "1").Client(replay.Client()).MustString())
+	s.Equal([]byte{0xff, 0xfe}, kit.Req(url).Post().StringBody("bin").Client(replay.Client()).MustBytes())
+	s.Equal(3, count)
+
+	// each interaction can only be replayed once
+	err := kit.Req(url).Post().StringBody("x").Header("a", "1").Client(replay.Client()).Do()
+	s.True(errors.Is(err, kit.ErrCassetteNoMatch))
+	s.Contains(err.Error(), "POST "+url)
+}
+
+func (s *RequestSuite) TestCassetteRedact() {
+	path, url := s.path()
+
+	s.router.GET(path, func(c kit.GinContext) {
+		c.Header("X-Secret", "s")
+		c.String(200, "ok")
+	})
+
+	p := filepath.Join("tmp", kit.RandString(8), "cassette.json")
+	defer func() { _ = kit.Remove("tmp") }()
+
+	record := kit.MustCassette(p, kit.CassetteRecord).Filter(func(req, res http.Header) {
+		res.Del("X-Secret")
+	})
+	res := kit.Req(url).Header("Authorization", "token", "cookie", "a=1", "b", "2").Client(record.Client()).MustResponse()
+	s.Equal("s", res.Header.Get("X-Secret"))
+
+	data, err := kit.ReadString(p)
+	kit.E(err)
+	s.NotContains(data, "token")
+	s.NotContains(data, "a=1")
+	s.NotContains(data, "X-Secret")
+	s.Contains(data, `"[REDACTED]"`)
+	s.Contains(data, `"2"`)
+
+	replay := kit.MustCassette(p, kit.CassetteReplay)
+	s.Equal("ok", kit.Req(url).Header("Authorization", "other").Client(replay.Client()).MustString())
+}
+
+func (s *RequestSuite) TestCassetteErr() {
+	_, err := kit.NewCassette("not-exists", kit.CassetteReplay)
+	s.Error(err)
+
+	p := filepath.Join("tmp", kit.RandString(8), "cassette.json")
+	defer func() { _ = kit.Remove("tmp") }()
+
+	c := kit.MustCassette(p, kit.CassetteRecord)
+	s.Error(kit.Req("http://127.0.0.1:1").Client(c.Client()).Do())
+}
@@ -0,0 +1,149 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// AcceptEncoding is the encodings that the response decompression supports
+const AcceptEncoding = "gzip, deflate, br, zstd"
+
+var decoders = map[string]func(io.Reader) (io.ReadCloser, error){
+	"gzip": func(r io.Reader) (io.ReadCloser, error) {
+		return gzip.NewReader(r)
+	},
+	"deflate": func(r io.Reader) (io.ReadCloser, error) {
+		// some servers send the raw deflate instead of the zlib format
+		br := bufio.NewReader(r)
+		h, err := br.Peek(2)
+		if err == nil && h[0]&0x0f == 8 && (uint16(h[0])<<8|uint16(h[1]))%31 == 0 {
+			return zlib.NewReader(br)
+		}
+		return flate.NewReader(br), nil
+	},
+	"br": func(r io.Reader) (io.ReadCloser, error) {
+		return ioutil.NopCloser(brotli.NewReader(r)), nil
+	},
+	"zstd": func(r io.Reader) (io.ReadCloser, error) {
+		d, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return d.IOReadCloser(), nil
+	},
+}
+
+var encoders = map[string]func(io.Writer) (io.WriteCloser, error){
+	"gzip": func(w io.Writer) (io.WriteCloser, error) {
+		return gzip.NewWriter(w), nil
+	},
+	"deflate": func(w io.Writer) (io.WriteCloser, error) {
+		return zlib.NewWriter(w), nil
+	},
+	"br": func(w io.Writer) (io.WriteCloser, error) {
+		return brotli.NewWriter(w), nil
+	},
+	"zstd": func(w io.Writer) (io.WriteCloser, error) {
+		return zstd.NewWriter(w)
+	},
+}
+
+// Compress compresses the request body with the encoding, and sets the Content-Encoding header.
+// The encoding can be gzip, deflate, br or zstd. The body will be read into memory before being compressed.
+// The multipart body and the empty body won't be compressed.
+func (ctx *ReqContext) Compress(encoding string) *ReqContext {
+	ctx.compress = encoding
+	return ctx
+}
+
+// compressBody only sets the Content-Encoding header when the body is really compressed
+func (ctx *ReqContext) compressBody(body io.Reader) (io.Reader, error) {
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return bytes.NewReader(data), nil
+	}
+
+	data, err = compress(ctx.compress, data)
+	if err != nil {
+		return nil, err
+	}
+	ctx.header["Content-Encoding"] = []string{ctx.compress}
+
+	return bytes.NewReader(data), nil
+}
+
+func compress(encoding string, data []byte) ([]byte, error) {
+	newEncoder, has := encoders[encoding]
+	if !has {
+		return nil, fmt.Errorf("unsupported encoding: %s", encoding)
+	}
+
+	buf := bytes.NewBuffer(nil)
+	w, err := newEncoder(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = w.Write(data)
+	if err != nil {
+		return nil, err
+	}
+
+	err = w.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// decompress replaces the response body with the decoded one according to the Content-Encoding,
+// the unknown encoding will be kept as it is
+func decompress(res *http.Response) error {
+	encoding := strings.ToLower(strings.TrimSpace(res.Header.Get("Content-Encoding")))
+	newDecoder, has := decoders[encoding]
+	if !has {
+		return nil
+	}
+
+	body := res.Body
+	r, err := newDecoder(body)
+	if err == io.EOF {
+		// empty body
+		r = ioutil.NopCloser(body)
+	} else if err != nil {
+		return err
+	}
+
+	res.Body = &decodedBody{r, body}
+	res.Header.Del("Content-Encoding")
+	res.Header.Del("Content-Length")
+	res.ContentLength = -1
+	res.Uncompressed = true
+
+	return nil
+}
+
+type decodedBody struct {
+	io.ReadCloser
+	origin io.Closer
+}
+
+func (b *decodedBody) Close() error {
+	_ = b.ReadCloser.Close()
+	return b.origin.Close()
+}
@@ -0,0 +1,142 @@
+package http_test
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+	"github.com/ysmood/kit"
+)
+
+func encode(encoding string, data string) []byte {
+	buf := bytes.NewBuffer(nil)
+	var w io.WriteCloser
+	switch encoding {
+	case "gzip":
+		w = gzip.NewWriter(buf)
+	case "deflate":
+		w = zlib.NewWriter(buf)
+	case "raw-deflate":
+		w, _ = flate.NewWriter(buf, flate.DefaultCompression)
+	case "br":
+		w = brotli.NewWriter(buf)
+	case "zstd":
+		w, _ = zstd.NewWriter(buf)
+	}
+	_, _ = w.Write([]byte(data))
+	kit.E(w.Close())
+	return buf.Bytes()
+}
+
+func (s *RequestSuite) TestDecompress() {
+	path, url := s.path()
+
+	s.router.GET(path, func(c kit.GinContext) {
+		encoding := c.Query("e")
+		c.Header("Content-Encoding", encoding)
+		if encoding == "raw-deflate" {
+			c.Header("Content-Encoding", "deflate")
+		}
+		c.Data(200, "", encode(encoding, "a\nb"))
+	})
+
+	for _, encoding := range []string{"gzip", "deflate", "raw-deflate", "br", "zstd"} {
+		c := kit.Req(url).Query("e", encoding).Header("Accept-Encoding", kit.AcceptEncoding)
+		lines := []string{}
+		kit.E(c.Lines(func(l string) error {
+			lines = append(lines, l)
+			return nil
+		}))
+		s.Equal([]string{"a", "b"}, lines, encoding)
+		s.Equal("", c.MustResponse().Header.Get("Content-Encoding"))
+	}
+}
+
+func (s *RequestSuite) TestDecompressEmpty() {
+	path, url := s.path()
+
+	s.router.GET(path, func(c kit.GinContext) {
+		c.Header("Content-Encoding", "gzip")
+	})
+
+	s.Equal("", kit.Req(url).MustString())
+}
+
+func (s *RequestSuite) TestDecompressErr() {
+	path, url := s.path()
+
+	s.router.GET(path, func(c kit.GinContext) {
+		c.Header("Content-Encoding", "gzip")
+		c.String(200, "not a gzip content")
+	})
+
+	_, err := kit.Req(url).String()
+	s.EqualError(err, "gzip: invalid header")
+
+	s.EqualError(kit.Req(url).Header("Accept-Encoding", kit.AcceptEncoding).Do(), "gzip: invalid header")
+}
+
+func (s *RequestSuite) TestCompress() {
+	path, url := s.path()
+
+	s.router.POST(path, func(c kit.GinContext) {
+		var r io.Reader = c.Request.Body
+		switch c.GetHeader("Content-Encoding") {
+		case "gzip":
+			r, _ = gzip.NewReader(r)
+		case "zstd":
+			d, _ := zstd.NewReader(r)
+			r = d
+		}
+		data, _ := ioutil.ReadAll(r)
+		c.String(200, string(data))
+	})
+
+	s.Equal("ok", kit.Req(url).Post().StringBody("ok").Compress("gzip").MustString())
+	s.Equal(`{"a":1}`, kit.Req(url).Post().JSONBody(map[string]int{"a": 1}).Compress("zstd").MustString())
+	s.EqualError(kit.Req(url).Post().StringBody("ok").Compress("x").Do(), "unsupported encoding: x")
+
+	s.Equal("a=1", kit.Req(url).Post().Form("a", "1").Compress("gzip").MustString())
+	s.Equal("ok", kit.Req(url).Post().Body(strings.NewReader("ok")).Compress("zstd").MustString())
+}
+
+func (s *RequestSuite) TestCompressSkip() {
+	path, url := s.path()
+
+	s.router.POST(path, func(c kit.GinContext) {
+		c.String(200, c.GetHeader("Content-Encoding")+c.PostForm("a"))
+	})
+
+	s.Equal("ok", kit.Req(url).Post().Multipart("a", "ok").Compress("gzip").MustString())
+	s.Equal("", kit.Req(url).Post().Compress("gzip").MustString())
+	s.Equal("", kit.Req(url).Post().StringBody("").Compress("gzip").MustString())
+}
+
+func (s *RequestSuite) TestCompressCurl() {
+	path, url := s.path()
+
+	s.router.POST(path, func(c kit.GinContext) {
+		c.String(200, c.GetHeader("Content-Encoding"))
+	})
+
+	c := kit.Req(url).Post().StringBody("ok").Compress("gzip")
+	s.Equal(kit.S(`curl -X POST {{.url}} \
+  -d ok
+
+HTTP/1.1 200 OK
+Content-Length: 4
+Content-Type: text/plain; charset=utf-8
+Date: {{.date}}
+
+gzip`, "url", url, "date", c.MustResponse().Header.Get("Date")), c.MustCurl())
+
+	snippet, err := kit.Req(url).Post().StringBody("ok").Compress("gzip").GoSnippet()
+	kit.E(err)
+	s.Contains(snippet, `Compress("gzip")`)
+}
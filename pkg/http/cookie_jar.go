@@ -0,0 +1,171 @@
+package http
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	gos "github.com/ysmood/kit/pkg/os"
+	"github.com/ysmood/kit/pkg/utils"
+)
+
+// CookieJar is a cookie jar that can be saved to and loaded from a json file.
+// It's safe for concurrent use.
+type CookieJar struct {
+	lock    sync.Mutex
+	path    string
+	jar     *cookiejar.Jar
+	entries map[string]*jarEntry
+}
+
+type jarEntry struct {
+	URL    string       `json:"url"`
+	Cookie *http.Cookie `json:"cookie"`
+}
+
+// NewCookieJar creates a jar that persists the cookies to the json file of the path,
+// if the file exists the cookies in it will be loaded
+func NewCookieJar(path string) (*CookieJar, error) {
+	j := &CookieJar{path: path}
+	return j, j.Load()
+}
+
+// MustCookieJar panic version of NewCookieJar
+func MustCookieJar(path string) *CookieJar {
+	return utils.E(NewCookieJar(path))[0].(*CookieJar)
+}
+
+// SetCookies implements the http.CookieJar interface
+func (j *CookieJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	j.jar.SetCookies(u, cookies)
+
+	now := time.Now()
+	for _, c := range cookies {
+		key := jarKey(u, c)
+
+		if c.MaxAge < 0 || (!c.Expires.IsZero() && c.Expires.Before(now)) {
+			delete(j.entries, key)
+			continue
+		}
+
+		// the max-age is relative, store it as the absolute time
+		stored := *c
+		if c.MaxAge > 0 {
+			stored.Expires = now.Add(time.Duration(c.MaxAge) * time.Second)
+			stored.MaxAge = 0
+		}
+
+		j.entries[key] = &jarEntry{URL: u.String(), Cookie: &stored}
+	}
+}
+
+// Cookies implements the http.CookieJar interface
+func (j *CookieJar) Cookies(u *url.URL) []*http.Cookie {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	return j.jar.Cookies(u)
+}
+
+// Save writes the cookies that are not expired to the file
+func (j *CookieJar) Save() error {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	list := []*jarEntry{}
+	now := time.Now()
+	for _, e := range j.entries {
+		if e.Cookie.Expires.IsZero() || e.Cookie.Expires.After(now) {
+			list = append(list, e)
+		}
+	}
+
+	// write to a temp file then rename it, so that the file won't be half written
+	tmp := j.path + ".tmp"
+	err := gos.OutputFile(tmp, list, &gos.OutputFileOptions{
+		DirPerm:    0700,
+		FilePerm:   0600,
+		JSONIndent: "  ",
+	})
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, j.path)
+}
+
+// Load replaces the cookies in the jar with the ones in the file
+func (j *CookieJar) Load() error {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	j.jar, _ = cookiejar.New(nil)
+	j.entries = map[string]*jarEntry{}
+
+	if !gos.FileExists(j.path) {
+		return nil
+	}
+
+	list := []*jarEntry{}
+	err := gos.ReadJSON(j.path, &list)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, e := range list {
+		if !e.Cookie.Expires.IsZero() && e.Cookie.Expires.Before(now) {
+			continue
+		}
+
+		u, err := url.Parse(e.URL)
+		if err != nil {
+			return err
+		}
+
+		j.jar.SetCookies(u, []*http.Cookie{e.Cookie})
+		j.entries[jarKey(u, e.Cookie)] = e
+	}
+
+	return nil
+}
+
+// jarKey identifies a cookie the same way as the jar does, a domain cookie is shared by all the hosts
+// of the domain, so only the host-only cookie is keyed by the host
+func jarKey(u *url.URL, c *http.Cookie) string {
+	if c.Domain != "" {
+		return strings.ToLower(strings.TrimPrefix(c.Domain, ".")) + ";" + c.Path + ";" + c.Name
+	}
+	return strings.ToLower(u.Hostname()) + ";;" + c.Path + ";" + c.Name
+}
+
+// Jar sets the cookie jar of the request, such as the CookieJar
+func (ctx *ReqContext) Jar(jar http.CookieJar) *ReqContext {
+	if ctx.client == nil {
+		ctx.client = newClient()
+	} else {
+		// don't modify the client that may be shared
+		c := *ctx.client
+		ctx.client = &c
+	}
+	ctx.client.Jar = jar
+	return ctx
+}
+
+// Jar sets the cookie jar of the session, such as the CookieJar
+func (s *SessionContext) Jar(jar http.CookieJar) *SessionContext {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	c := *s.client
+	c.Jar = jar
+	s.client = &c
+	return s
+}
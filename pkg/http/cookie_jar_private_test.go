@@ -0,0 +1,40 @@
+package http
+
+import (
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	gos "github.com/ysmood/kit/pkg/os"
+	"github.com/ysmood/kit/pkg/utils"
+)
+
+func TestCookieJarExpiry(t *testing.T) {
+	p := filepath.Join("tmp", utils.RandString(8), "cookies.json")
+	defer func() { _ = gos.Remove("tmp") }()
+
+	u, _ := url.Parse("http://test.com")
+
+	jar := MustCookieJar(p)
+	jar.SetCookies(u, []*http.Cookie{
+		{Name: "a", Value: "1", MaxAge: 60},
+		{Name: "b", Value: "1", Expires: time.Now().Add(time.Hour)},
+		{Name: "c", Value: "1"},
+	})
+	jar.SetCookies(u, []*http.Cookie{{Name: "c", MaxAge: -1}})
+
+	// it will expire before the save
+	jar.entries[jarKey(u, &http.Cookie{Name: "b"})].Cookie.Expires = time.Now().Add(-time.Second)
+
+	utils.E(jar.Save())
+
+	jar = MustCookieJar(p)
+	if len(jar.entries) != 1 || jar.entries[jarKey(u, &http.Cookie{Name: "a"})].Cookie.Expires.IsZero() {
+		panic("only the cookie a should be saved")
+	}
+	if len(jar.Cookies(u)) != 1 {
+		panic("only the cookie a should be loaded")
+	}
+}
@@ -0,0 +1,70 @@
+package http_test
+
+import (
+	"net/http"
+	"net/url"
+	"path/filepath"
+
+	"github.com/ysmood/kit"
+)
+
+func (s *RequestSuite) TestCookieJar() {
+	path, url := s.path()
+
+	s.router.GET(path, func(c kit.GinContext) {
+		cookie, _ := c.Cookie("t")
+		c.SetCookie("t", "val", 3600, "", "", false, true)
+		c.SetCookie("session", "val", 0, "", "", false, true)
+		c.SetCookie("expired", "val", -1, "", "", false, true)
+		c.String(200, cookie)
+	})
+
+	p := filepath.Join("tmp", kit.RandString(8), "cookies.json")
+	defer func() { _ = kit.Remove("tmp") }()
+
+	jar := kit.MustCookieJar(p)
+	s.Equal("", kit.Req(url).Jar(jar).MustString())
+	kit.E(jar.Save())
+
+	jar = kit.MustCookieJar(p)
+	s.Equal("val", kit.Req(url).Jar(jar).MustString())
+	s.Equal("val", kit.Session().Jar(jar).Req(url).MustString())
+
+	var saved []struct{ Cookie struct{ Name string } }
+	kit.E(kit.ReadJSON(p, &saved))
+	s.Len(saved, 2)
+}
+
+func (s *RequestSuite) TestCookieJarDomain() {
+	p := filepath.Join("tmp", kit.RandString(8), "cookies.json")
+	defer func() { _ = kit.Remove("tmp") }()
+
+	jar := kit.MustCookieJar(p)
+	set := func(raw, val string) {
+		u, _ := url.Parse(raw)
+		jar.SetCookies(u, []*http.Cookie{
+			{Name: "t", Value: val, Domain: "test.com", Path: "/"},
+		})
+	}
+	set("http://a.test.com", "a")
+	set("http://b.test.com", "b")
+	kit.E(jar.Save())
+
+	var saved []interface{}
+	kit.E(kit.ReadJSON(p, &saved))
+	s.Len(saved, 1)
+
+	jar = kit.MustCookieJar(p)
+	u, _ := url.Parse("http://c.test.com")
+	s.Equal("b", jar.Cookies(u)[0].Value)
+}
+
+func (s *RequestSuite) TestCookieJarErr() {
+	p := filepath.Join("tmp", kit.RandString(8), "cookies.json")
+	defer func() { _ = kit.Remove("tmp") }()
+
+	kit.E(kit.OutputFile(p, "[", nil))
+
+	_, err := kit.NewCookieJar(p)
+	s.EqualError(err, "unexpected end of JSON input")
+}
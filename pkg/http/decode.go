@@ -0,0 +1,62 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/ysmood/kit/pkg/utils"
+)
+
+// StrictDecode makes Decode reject the json fields that don't exist in the target struct
+func (ctx *ReqContext) StrictDecode() *ReqContext {
+	ctx.strictDecode = true
+	return ctx
+}
+
+// Decode sends request, then decodes the json response body into v
+func (ctx *ReqContext) Decode(v interface{}) error {
+	b, err := ctx.Bytes()
+	if err != nil {
+		return err
+	}
+
+	return ctx.decode(b, v)
+}
+
+// MustDecode panic version of Decode()
+func (ctx *ReqContext) MustDecode(v interface{}) {
+	utils.E(ctx.Decode(v))
+}
+
+// DecodeStatus sends request, if the status code is 2xx decodes the response body into v,
+// else decodes the body into errV and returns errV as the error.
+// errV should be a pointer, such as DecodeStatus(&data, &APIError{})
+func (ctx *ReqContext) DecodeStatus(v interface{}, errV error) error {
+	res, err := ctx.Response()
+	if err != nil {
+		return err
+	}
+
+	b, err := ctx.Bytes()
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		err = ctx.decode(b, errV)
+		if err != nil {
+			return err
+		}
+		return errV
+	}
+
+	return ctx.decode(b, v)
+}
+
+func (ctx *ReqContext) decode(b []byte, v interface{}) error {
+	d := json.NewDecoder(bytes.NewReader(b))
+	if ctx.strictDecode {
+		d.DisallowUnknownFields()
+	}
+	return d.Decode(v)
+}
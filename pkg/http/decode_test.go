@@ -0,0 +1,76 @@
+package http_test
+
+import (
+	"github.com/ysmood/kit"
+)
+
+type apiError struct {
+	Message string `json:"message"`
+}
+
+func (e *apiError) Error() string {
+	return e.Message
+}
+
+func (s *RequestSuite) TestDecode() {
+	path, url := s.path()
+
+	s.router.GET(path, func(c kit.GinContext) {
+		c.String(200, `{"a": 1, "b": "x"}`)
+	})
+
+	var data struct{ A int }
+	kit.Req(url).MustDecode(&data)
+	s.Equal(1, data.A)
+}
+
+func (s *RequestSuite) TestDecodeStrict() {
+	path, url := s.path()
+
+	s.router.GET(path, func(c kit.GinContext) {
+		c.String(200, `{"a": 1, "b": "x"}`)
+	})
+
+	var data struct{ A int }
+	err := kit.Req(url).StrictDecode().Decode(&data)
+	s.EqualError(err, `json: unknown field "b"`)
+}
+
+func (s *RequestSuite) TestDecodeErr() {
+	var data struct{}
+	s.EqualError(kit.Req("").Decode(&data), "Get \"\": unsupported protocol scheme \"\"")
+	s.EqualError(kit.Req("").DecodeStatus(&data, &apiError{}), "Get \"\": unsupported protocol scheme \"\"")
+}
+
+func (s *RequestSuite) TestDecodeStatus() {
+	path, url := s.path()
+
+	s.router.GET(path, func(c kit.GinContext) {
+		if c.Query("fail") != "" {
+			c.String(400, `{"message": "bad request"}`)
+			return
+		}
+		c.String(200, `{"a": 1}`)
+	})
+
+	var data struct{ A int }
+	kit.E(kit.Req(url).DecodeStatus(&data, &apiError{}))
+	s.Equal(1, data.A)
+
+	err := kit.Req(url).Query("fail", "1").DecodeStatus(&data, &apiError{})
+	apiErr, ok := err.(*apiError)
+	s.True(ok)
+	s.Equal("bad request", apiErr.Message)
+}
+
+func (s *RequestSuite) TestDecodeStatusInvalidErrBody() {
+	path, url := s.path()
+
+	s.router.GET(path, func(c kit.GinContext) {
+		c.String(500, `not json`)
+	})
+
+	var data struct{}
+	err := kit.Req(url).DecodeStatus(&data, &apiError{})
+	s.EqualError(err, "invalid character 'o' in literal null (expecting 'u')")
+}
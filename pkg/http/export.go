@@ -0,0 +1,418 @@
+package http
+
+import (
+	"encoding/base64"
+	"errors"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/alessio/shellescape"
+	"github.com/ysmood/kit/pkg/utils"
+)
+
+// ErrWgetMultipart is returned when export multipart request as wget command
+var ErrWgetMultipart = errors.New("wget doesn't support multipart form")
+
+// CurlCommand generates the curl command of the request without sending it
+func (ctx *ReqContext) CurlCommand() (string, error) {
+	data := ctx.curlMultipart()
+	if ctx.multipart == nil {
+		body, err := ctx.peekBody()
+		if err != nil {
+			return "", err
+		}
+		data = curlData(body)
+	}
+
+	req, err := ctx.exportRequest()
+	if err != nil {
+		return "", err
+	}
+
+	return formatCurl(req, data), nil
+}
+
+// HTTPie generates the HTTPie command of the request without sending it
+func (ctx *ReqContext) HTTPie() (string, error) {
+	body, err := ctx.peekBody()
+	if err != nil {
+		return "", err
+	}
+
+	req, err := ctx.exportRequest()
+	if err != nil {
+		return "", err
+	}
+
+	cmd := "http"
+	if ctx.multipart != nil {
+		cmd += " --multipart"
+	} else if body != "" {
+		cmd += " --raw " + shellescape.Quote(body)
+	}
+	cmd += " " + req.Method + " " + shellescape.Quote(req.URL.String())
+
+	for _, h := range headerToArr(req.Header) {
+		cmd += " \\\n  " + shellescape.Quote(h[0]+":"+h[1])
+	}
+
+	for _, p := range ctx.multipart {
+		if p.path == "" {
+			cmd += " \\\n  " + shellescape.Quote(p.field+"="+p.value)
+		} else {
+			cmd += " \\\n  " + shellescape.Quote(p.field+"@"+p.path)
+		}
+	}
+
+	return cmd, nil
+}
+
+// Wget generates the wget command of the request without sending it
+func (ctx *ReqContext) Wget() (string, error) {
+	if ctx.multipart != nil {
+		return "", ErrWgetMultipart
+	}
+
+	body, err := ctx.peekBody()
+	if err != nil {
+		return "", err
+	}
+
+	req, err := ctx.exportRequest()
+	if err != nil {
+		return "", err
+	}
+
+	cmd := "wget -O - --method=" + req.Method
+	for _, h := range headerToArr(req.Header) {
+		cmd += " \\\n  --header=" + shellescape.Quote(h[0]+": "+h[1])
+	}
+	if body != "" {
+		cmd += " \\\n  --body-data=" + shellescape.Quote(body)
+	}
+
+	return cmd + " \\\n  " + shellescape.Quote(req.URL.String()), nil
+}
+
+// GoSnippet generates the go code of the request without sending it
+func (ctx *ReqContext) GoSnippet() (string, error) {
+	body, err := ctx.peekBody()
+	if err != nil {
+		return "", err
+	}
+
+	req, err := ctx.exportRequest()
+	if err != nil {
+		return "", err
+	}
+
+	code := "kit.Req(" + strconv.Quote(req.URL.String()) + ")"
+	if req.Method != http.MethodGet {
+		code += ".\n\tMethod(" + strconv.Quote(req.Method) + ")"
+	}
+	if req.Host != "" {
+		code += ".\n\tHost(" + strconv.Quote(req.Host) + ")"
+	}
+	for _, h := range headerToArr(req.Header) {
+		code += ".\n\tHeader(" + strconv.Quote(h[0]) + ", " + strconv.Quote(h[1]) + ")"
+	}
+	if ctx.compress != "" {
+		code += ".\n\tCompress(" + strconv.Quote(ctx.compress) + ")"
+	}
+	for _, p := range ctx.multipart {
+		if p.path == "" {
+			code += ".\n\tMultipart(" + strconv.Quote(p.field) + ", " + strconv.Quote(p.value) + ")"
+		} else {
+			code += ".\n\tFile(" + strconv.Quote(p.field) + ", " + strconv.Quote(p.path) + ")"
+		}
+	}
+	if body != "" {
+		code += ".\n\tStringBody(" + strconv.Quote(body) + ")"
+	}
+
+	return code + ".\n\tMustString()", nil
+}
+
+// HAR is the http archive 1.2 document
+type HAR struct {
+	Log HARLog `json:"log"`
+}
+
+// HARLog of HAR
+type HARLog struct {
+	Version string      `json:"version"`
+	Creator HARCreator  `json:"creator"`
+	Entries []*HAREntry `json:"entries"`
+}
+
+// HARCreator of HAR
+type HARCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// HAREntry of HAR
+type HAREntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         HARRequest  `json:"request"`
+	Response        HARResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         HARTimings  `json:"timings"`
+}
+
+// HARRequest of HAR
+type HARRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARNameValue `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	QueryString []HARNameValue `json:"queryString"`
+	PostData    *HARPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+// HARResponse of HAR
+type HARResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARNameValue `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	Content     HARContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+// HARNameValue of HAR
+type HARNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// HARPostData of HAR
+type HARPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+// HARContent of HAR
+type HARContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// HARTimings of HAR, the unit is millisecond, -1 means not available
+type HARTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+	SSL     float64 `json:"ssl"`
+}
+
+// NewHAR creates a HAR document with the entries
+func NewHAR(entries ...*HAREntry) *HAR {
+	return &HAR{Log: HARLog{
+		Version: "1.2",
+		Creator: HARCreator{Name: "kit", Version: utils.Version},
+		Entries: entries,
+	}}
+}
+
+// HAR sends request, then generates the HAR entry of the request and response
+func (ctx *ReqContext) HAR() (*HAREntry, error) {
+	body := ""
+	if ctx.multipart == nil {
+		var err error
+		body, err = ctx.peekBody()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	res, err := ctx.Response()
+	if err != nil {
+		return nil, err
+	}
+
+	resBytes, err := ctx.Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	req := ctx.request
+
+	entry := &HAREntry{
+		StartedDateTime: ctx.startTime.Format(time.RFC3339Nano),
+		Time:            ms(ctx.duration),
+		Request: HARRequest{
+			Method:      req.Method,
+			URL:         req.URL.String(),
+			HTTPVersion: req.Proto,
+			Cookies:     harCookies(req.Cookies()),
+			Headers:     harHeaders(req.Header),
+			QueryString: []HARNameValue{},
+			HeadersSize: -1,
+			BodySize:    len(body),
+		},
+		Response: HARResponse{
+			Status:      res.StatusCode,
+			StatusText:  http.StatusText(res.StatusCode),
+			HTTPVersion: res.Proto,
+			Cookies:     harCookies(res.Cookies()),
+			Headers:     harHeaders(res.Header),
+			Content:     harContent(res.Header.Get("Content-Type"), resBytes),
+			RedirectURL: res.Header.Get("Location"),
+			HeadersSize: -1,
+			BodySize:    len(resBytes),
+		},
+		Timings: HARTimings{
+			Blocked: -1,
+			DNS:     -1,
+			Connect: -1,
+			Send:    0,
+			Wait:    ms(ctx.duration),
+			Receive: 0,
+			SSL:     -1,
+		},
+	}
+
+	if t := ctx.timing; t != nil {
+		// in HAR the connect includes the ssl
+		entry.Timings.DNS = harPhase(t.DNS)
+		entry.Timings.Connect = harPhase(t.Connect + t.TLS)
+		entry.Timings.SSL = harPhase(t.TLS)
+		if t.Wait > 0 {
+			entry.Timings.Wait = ms(t.Wait)
+			entry.Timings.Send = math.Max(0, ms(t.TTFB-t.Wait-t.DNS-t.Connect-t.TLS))
+			entry.Timings.Receive = ms(t.Total - t.TTFB)
+		}
+	}
+
+	for k, vs := range req.URL.Query() {
+		for _, v := range vs {
+			entry.Request.QueryString = append(entry.Request.QueryString, HARNameValue{k, v})
+		}
+	}
+
+	if ctx.multipart != nil {
+		entry.Request.BodySize = -1
+		entry.Request.PostData = &HARPostData{MimeType: req.Header.Get("Content-Type")}
+		for _, p := range ctx.multipart {
+			if p.path == "" {
+				entry.Request.PostData.Text += p.field + "=" + p.value + "\n"
+			} else {
+				entry.Request.PostData.Text += p.field + "=@" + p.path + "\n"
+			}
+		}
+	} else if body != "" {
+		entry.Request.PostData = &HARPostData{MimeType: req.Header.Get("Content-Type"), Text: body}
+	}
+
+	return entry, nil
+}
+
+// MustHAR panic version of HAR()
+func (ctx *ReqContext) MustHAR() *HAREntry {
+	return utils.E(ctx.HAR())[0].(*HAREntry)
+}
+
+// peekBody reads the body as string, the raw body will be kept as the string body,
+// so that it can still be sent
+func (ctx *ReqContext) peekBody() (string, error) {
+	if ctx.multipart != nil {
+		return "", nil
+	}
+
+	body, err := ctx.getBody()
+	if err != nil || body == nil {
+		return "", err
+	}
+
+	b, err := ioutil.ReadAll(body)
+	if err != nil {
+		return "", err
+	}
+	ctx.stringBody = string(b)
+
+	return ctx.stringBody, nil
+}
+
+// exportRequest gets the request for exporting, it won't call the Request, because the exporting
+// doesn't send the request, the multipart body and the timeout shouldn't be started
+func (ctx *ReqContext) exportRequest() (*http.Request, error) {
+	if ctx.err != nil {
+		return nil, ctx.err
+	}
+
+	req, err := http.NewRequest(ctx.method, ctx.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = ctx.header.Clone()
+	req.Host = ctx.host
+
+	if ctx.multipart != nil {
+		// the tools generate their own boundaries
+		req.Header.Del("Content-Type")
+	}
+
+	if ctx.compress != "" {
+		// the exported body is the uncompressed one
+		req.Header.Del("Content-Encoding")
+	}
+
+	return req, nil
+}
+
+func harHeaders(h http.Header) []HARNameValue {
+	list := []HARNameValue{}
+	for _, kv := range headerToArr(h) {
+		list = append(list, HARNameValue{kv[0], kv[1]})
+	}
+	return list
+}
+
+func harCookies(cookies []*http.Cookie) []HARNameValue {
+	list := []HARNameValue{}
+	for _, c := range cookies {
+		list = append(list, HARNameValue{c.Name, c.Value})
+	}
+	return list
+}
+
+func harContent(mimeType string, b []byte) HARContent {
+	if utf8.Valid(b) {
+		return HARContent{Size: len(b), MimeType: mimeType, Text: string(b)}
+	}
+	return HARContent{
+		Size:     len(b),
+		MimeType: mimeType,
+		Text:     base64.StdEncoding.EncodeToString(b),
+		Encoding: "base64",
+	}
+}
+
+// the phase that didn't happen is -1
+func harPhase(d time.Duration) float64 {
+	if d == 0 {
+		return -1
+	}
+	return ms(d)
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,119 @@
+package http_test
+
+import (
+	"time"
+
+	"github.com/ysmood/kit"
+)
+
+func (s *RequestSuite) TestCurlCommand() {
+	c := kit.Req("http://test.com/a").Post().Header("A", "b").StringBody("x'y")
+
+	cmd, err := c.CurlCommand()
+	kit.E(err)
+
+	s.Equal(`curl -X POST http://test.com/a \
+  -H 'A: b' \
+  -d 'x'"'"'y'`, cmd)
+
+	_, err = kit.Req("").Method("あ").CurlCommand()
+	s.Error(err)
+}
+
+func (s *RequestSuite) TestCurlCommandNotSend() {
+	path, url := s.path()
+
+	s.router.POST(path, func(c kit.GinContext) {
+		c.String(200, c.PostForm("a"))
+	})
+
+	// the export shouldn't start the timeout or the multipart body
+	c := kit.Req(url).Post().Timeout(50*time.Millisecond).Multipart("a", "ok")
+	_, err := c.CurlCommand()
+	kit.E(err)
+	time.Sleep(100 * time.Millisecond)
+
+	s.Equal("ok", c.MustString())
+}
+
+func (s *RequestSuite) TestHTTPie() {
+	cmd, err := kit.Req("http://test.com/a").Post().Header("A", "b").StringBody("x").HTTPie()
+	kit.E(err)
+	s.Equal(`http --raw x POST http://test.com/a \
+  A:b`, cmd)
+
+	cmd, err = kit.Req("http://test.com/a").Post().Multipart("a", "1").File("f", "a.txt").HTTPie()
+	kit.E(err)
+	s.Equal(`http --multipart POST http://test.com/a \
+  a=1 \
+  f@a.txt`, cmd)
+}
+
+func (s *RequestSuite) TestWget() {
+	cmd, err := kit.Req("http://test.com/a").Put().Header("A", "b").StringBody("x").Wget()
+	kit.E(err)
+	s.Equal(`wget -O - --method=PUT \
+  --header='A: b' \
+  --body-data=x \
+  http://test.com/a`, cmd)
+
+	_, err = kit.Req("http://test.com").Multipart("a", "1").Wget()
+	s.Equal(kit.ErrWgetMultipart, err)
+}
+
+func (s *RequestSuite) TestGoSnippet() {
+	code, err := kit.Req("http://test.com/a").Post().Host("b.com").JSONBody(map[string]int{"a": 1}).GoSnippet()
+	kit.E(err)
+	s.Equal(`kit.Req("http://test.com/a").
+	Method("POST").
+	Host("b.com").
+	Header("Content-Type", "application/json; charset=utf-8").
+	StringBody("{\"a\":1}").
+	MustString()`, code)
+
+	code, err = kit.Req("http://test.com/a").Multipart("a", "1").File("f", "a.txt").GoSnippet()
+	kit.E(err)
+	s.Equal(`kit.Req("http://test.com/a").
+	Multipart("a", "1").
+	File("f", "a.txt").
+	MustString()`, code)
+}
+
+func (s *RequestSuite) TestHAR() {
+	path, url := s.path()
+
+	s.router.POST(path, func(c kit.GinContext) {
+		c.SetCookie("t", "v", 3600, "", "", false, true)
+		c.Data(200, "application/octet-stream", []byte{0xff})
+	})
+
+	entry := kit.Req(url).Post().Query("q", "1").StringBody("x").MustHAR()
+
+	s.Equal(url+"?q=1", entry.Request.URL)
+	s.Equal("x", entry.Request.PostData.Text)
+	s.Equal([]kit.HARNameValue{{Name: "q", Value: "1"}}, entry.Request.QueryString)
+	s.Equal(200, entry.Response.Status)
+	s.Equal([]kit.HARNameValue{{Name: "t", Value: "v"}}, entry.Response.Cookies)
+	s.Equal(kit.HARContent{Size: 1, MimeType: "application/octet-stream", Text: "/w==", Encoding: "base64"}, entry.Response.Content)
+	s.Equal(entry.Time, entry.Timings.Wait)
+
+	doc := kit.JSON(kit.MustToJSON(kit.NewHAR(entry)))
+	s.Equal("1.2", doc.Get("log.version").String())
+	s.Equal(int64(200), doc.Get("log.entries.0.response.status").Int())
+
+	_, err := kit.Req("").HAR()
+	s.Error(err)
+}
+
+func (s *RequestSuite) TestHARMultipart() {
+	path, url := s.path()
+
+	s.router.POST(path, func(c kit.GinContext) {
+		c.String(200, c.PostForm("a"))
+	})
+
+	entry := kit.Req(url).Post().Multipart("a", "1").MustHAR()
+
+	s.Equal("a=1\n", entry.Request.PostData.Text)
+	s.Equal("1", entry.Response.Content.Text)
+}
@@ -0,0 +1,273 @@
+package http
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	gos "github.com/ysmood/kit/pkg/os"
+	"github.com/ysmood/kit/pkg/utils"
+)
+
+// ErrCurlUnclosedQuote is returned when the quote in the curl command is not closed
+var ErrCurlUnclosedQuote = errors.New("unclosed quote in the curl command")
+
+// FromCurl parses the curl command into a request, the reverse of the CurlCommand.
+// Supports the options -X, -H, -d, --data-raw, --data-binary, -F, --form-string, -u, -b, -A,
+// --compressed, -k, the grouped short options such as -sSL and the line continuations.
+func FromCurl(cmd string) (*ReqContext, error) {
+	args, err := splitShellArgs(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(args) > 0 && args[0] == "curl" {
+		args = args[1:]
+	}
+
+	ctx := Req("")
+	data := []string{}
+	hasMethod := false
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		if !strings.HasPrefix(arg, "-") {
+			ctx.url = arg
+			continue
+		}
+
+		if group := splitCurlFlags(arg); group != nil {
+			args = append(append(append([]string{}, args[:i]...), group...), args[i+1:]...)
+			arg = args[i]
+		}
+
+		// options without value
+		switch arg {
+		case "--compressed":
+			ctx.Header("Accept-Encoding", AcceptEncoding)
+			continue
+		case "-L", "--location", "-s", "--silent", "-S", "--show-error",
+			"-i", "--include", "-v", "--verbose":
+			// the redirects are followed by the client by default
+			continue
+		case "-k", "--insecure":
+			ctx.Insecure()
+			continue
+		}
+
+		// options with value, the value can be joined with the short option, such as -XPOST
+		var val string
+		if !strings.HasPrefix(arg, "--") && len(arg) > 2 {
+			arg, val = arg[:2], arg[2:]
+		} else if i+1 < len(args) {
+			i++
+			val = args[i]
+		} else {
+			return nil, fmt.Errorf("curl option %s requires a value", arg)
+		}
+
+		switch arg {
+		case "-X", "--request":
+			ctx.Method(val)
+			hasMethod = true
+		case "--url":
+			ctx.url = val
+		case "-H", "--header":
+			kv := strings.SplitN(val, ":", 2)
+			if len(kv) != 2 {
+				return nil, fmt.Errorf("invalid curl header: %s", val)
+			}
+			ctx.Header(strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1]))
+		case "-d", "--data", "--data-ascii", "--data-binary":
+			if strings.HasPrefix(val, "@") {
+				val, err = gos.ReadString(val[1:])
+				if err != nil {
+					return nil, err
+				}
+				// like curl, only the newlines of the file are stripped
+				if arg != "--data-binary" {
+					val = strings.NewReplacer("\r", "", "\n", "").Replace(val)
+				}
+			}
+			data = append(data, val)
+		case "--data-raw":
+			data = append(data, val)
+		case "-F", "--form":
+			kv := strings.SplitN(val, "=", 2)
+			if len(kv) != 2 {
+				return nil, fmt.Errorf("invalid curl form: %s", val)
+			}
+			if strings.HasPrefix(kv[1], "@") {
+				ctx.File(kv[0], kv[1][1:])
+			} else {
+				ctx.Multipart(kv[0], kv[1])
+			}
+		case "--form-string":
+			kv := strings.SplitN(val, "=", 2)
+			if len(kv) != 2 {
+				return nil, fmt.Errorf("invalid curl form: %s", val)
+			}
+			ctx.Multipart(kv[0], kv[1])
+		case "-u", "--user":
+			ctx.Header("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(val)))
+		case "-b", "--cookie":
+			ctx.Header("Cookie", val)
+		case "-A", "--user-agent":
+			ctx.Header("User-Agent", val)
+		case "-e", "--referer":
+			ctx.Header("Referer", val)
+		default:
+			return nil, fmt.Errorf("unknown curl option: %s", arg)
+		}
+	}
+
+	if len(data) > 0 {
+		ctx.StringBody(strings.Join(data, "&"))
+		if len(headerValues(ctx.header, "Content-Type")) == 0 {
+			ctx.Header("Content-Type", "application/x-www-form-urlencoded")
+		}
+	}
+
+	if !hasMethod {
+		if len(data) > 0 || ctx.multipart != nil {
+			ctx.Method(http.MethodPost)
+		} else {
+			ctx.Method(http.MethodGet)
+		}
+	}
+
+	return ctx, nil
+}
+
+// the short options without value that can be grouped, such as -sSL
+const curlBoolFlags = "LsSivk"
+
+// splitCurlFlags splits the grouped short options, such as -sSL into -s -S -L, and -sXPOST into -s -XPOST.
+// It returns nil if the arg doesn't start with a short option without value.
+func splitCurlFlags(arg string) []string {
+	if strings.HasPrefix(arg, "--") || len(arg) <= 2 || strings.IndexByte(curlBoolFlags, arg[1]) < 0 {
+		return nil
+	}
+
+	list := []string{}
+	i := 1
+	for ; i < len(arg) && strings.IndexByte(curlBoolFlags, arg[i]) >= 0; i++ {
+		list = append(list, "-"+arg[i:i+1])
+	}
+	if i < len(arg) {
+		list = append(list, "-"+arg[i:])
+	}
+	return list
+}
+
+// MustFromCurl panic version of FromCurl
+func MustFromCurl(cmd string) *ReqContext {
+	return utils.E(FromCurl(cmd))[0].(*ReqContext)
+}
+
+// splitShellArgs splits the command into arguments like the posix shell, supports the single quotes,
+// double quotes, ansi-c quotes ($'...'), backslash escapes and line continuations
+func splitShellArgs(cmd string) ([]string, error) {
+	args := []string{}
+	cur := &strings.Builder{}
+	inArg := false
+	rs := []rune(cmd)
+
+	for i := 0; i < len(rs); i++ {
+		r := rs[i]
+
+		switch {
+		case r == '\\':
+			if i+1 < len(rs) {
+				i++
+				if rs[i] != '\n' { // line continuation
+					cur.WriteRune(rs[i])
+					inArg = true
+				}
+			}
+
+		case r == '\'':
+			end := indexRune(rs, i+1, '\'')
+			if end < 0 {
+				return nil, ErrCurlUnclosedQuote
+			}
+			cur.WriteString(string(rs[i+1 : end]))
+			i = end
+			inArg = true
+
+		case r == '$' && i+1 < len(rs) && rs[i+1] == '\'':
+			i += 2
+			for ; i < len(rs) && rs[i] != '\''; i++ {
+				if rs[i] == '\\' && i+1 < len(rs) {
+					i++
+					cur.WriteString(ansiEscape(rs[i]))
+					continue
+				}
+				cur.WriteRune(rs[i])
+			}
+			if i >= len(rs) {
+				return nil, ErrCurlUnclosedQuote
+			}
+			inArg = true
+
+		case r == '"':
+			i++
+			for ; i < len(rs) && rs[i] != '"'; i++ {
+				if rs[i] == '\\' && i+1 < len(rs) && strings.ContainsRune("\"\\$`\n", rs[i+1]) {
+					i++
+					if rs[i] == '\n' {
+						continue
+					}
+				}
+				cur.WriteRune(rs[i])
+			}
+			if i >= len(rs) {
+				return nil, ErrCurlUnclosedQuote
+			}
+			inArg = true
+
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inArg {
+				args = append(args, cur.String())
+				cur.Reset()
+				inArg = false
+			}
+
+		default:
+			cur.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if inArg {
+		args = append(args, cur.String())
+	}
+
+	return args, nil
+}
+
+func indexRune(rs []rune, from int, r rune) int {
+	for i := from; i < len(rs); i++ {
+		if rs[i] == r {
+			return i
+		}
+	}
+	return -1
+}
+
+func ansiEscape(r rune) string {
+	switch r {
+	case 'n':
+		return "\n"
+	case 'r':
+		return "\r"
+	case 't':
+		return "\t"
+	case '0':
+		return "\x00"
+	}
+	return string(r)
+}
@@ -0,0 +1,111 @@
+package http_test
+
+import (
+	"path/filepath"
+
+	"github.com/ysmood/kit"
+)
+
+func (s *RequestSuite) TestFromCurl() {
+	path, url := s.path()
+
+	s.router.POST(path, func(c kit.GinContext) {
+		u, p, _ := c.Request.BasicAuth()
+		data, _ := c.GetRawData()
+		cookie, _ := c.Cookie("t")
+		c.String(200, c.GetHeader("Content-Type")+"|"+c.GetHeader("A")+"|"+u+":"+p+"|"+cookie+"|"+string(data))
+	})
+
+	c := kit.MustFromCurl(`curl '` + url + `' \
+  -H 'a: "b"' \
+  -H "Content-Type: text/plain" \
+  -b 't=v' \
+  -u user:pass \
+  --data-raw $'x\ny' \
+  -d "'z'" \
+  --compressed`)
+
+	s.Equal("text/plain|\"b\"|user:pass|v|x\ny&'z'", c.MustString())
+}
+
+func (s *RequestSuite) TestFromCurlFlags() {
+	path, url := s.path()
+
+	s.router.PUT(path, func(c kit.GinContext) {
+		data, _ := c.GetRawData()
+		c.String(200, string(data))
+	})
+
+	s.Equal("a\nb", kit.MustFromCurl(`curl -sSL -sXPUT `+url+` -d $'a\nb'`).MustString())
+
+	p := filepath.Join("tmp", kit.RandString(8), "data.txt")
+	defer func() { _ = kit.Remove("tmp") }()
+	kit.E(kit.OutputFile(p, "a\r\nb\n", nil))
+
+	s.Equal("ab", kit.MustFromCurl(`curl -sS -X PUT `+url+` -d @`+p).MustString())
+	s.Equal("a\r\nb\n", kit.MustFromCurl(`curl -sS -X PUT `+url+` --data-binary @`+p).MustString())
+}
+
+func (s *RequestSuite) TestFromCurlRoundTrip() {
+	c := kit.Req("http://test.com/a?b=1").Put().Header("A", "it's").JSONBody(map[string]string{"a": "b c"})
+	cmd, err := c.CurlCommand()
+	kit.E(err)
+
+	parsed, err := kit.FromCurl(cmd)
+	kit.E(err)
+	parsedCmd, err := parsed.CurlCommand()
+	kit.E(err)
+
+	s.Equal(cmd, parsedCmd)
+}
+
+func (s *RequestSuite) TestFromCurlForm() {
+	path, url := s.path()
+
+	s.router.POST(path, func(c kit.GinContext) {
+		f, _ := c.FormFile("f")
+		c.String(200, c.Request.Method+c.PostForm("a")+c.PostForm("b")+f.Filename)
+	})
+
+	p := filepath.Join("tmp", kit.RandString(8), "a.txt")
+	kit.E(kit.OutputFile(p, "file", nil))
+	defer func() { _ = kit.Remove("tmp") }()
+
+	c := kit.MustFromCurl(`curl -F a=1 --form-string b=@x -F f=@` + p + ` -k ` + url)
+	s.Equal("POST1@xa.txt", c.MustString())
+
+	c = kit.MustFromCurl(`curl -XPOST -d @` + p + ` ` + url)
+	req, err := c.Request()
+	kit.E(err)
+	s.Equal("application/x-www-form-urlencoded", req.Header.Get("Content-Type"))
+	s.Equal("POST", req.Method)
+}
+
+func (s *RequestSuite) TestFromCurlErr() {
+	_, err := kit.FromCurl(`curl 'a`)
+	s.Equal(kit.ErrCurlUnclosedQuote, err)
+
+	_, err = kit.FromCurl(`curl "a`)
+	s.Equal(kit.ErrCurlUnclosedQuote, err)
+
+	_, err = kit.FromCurl(`curl $'a`)
+	s.Equal(kit.ErrCurlUnclosedQuote, err)
+
+	_, err = kit.FromCurl(`curl a -H`)
+	s.EqualError(err, "curl option -H requires a value")
+
+	_, err = kit.FromCurl(`curl a -H x`)
+	s.EqualError(err, "invalid curl header: x")
+
+	_, err = kit.FromCurl(`curl a -F x`)
+	s.EqualError(err, "invalid curl form: x")
+
+	_, err = kit.FromCurl(`curl a --form-string x`)
+	s.EqualError(err, "invalid curl form: x")
+
+	_, err = kit.FromCurl(`curl a --unknown x`)
+	s.EqualError(err, "unknown curl option: --unknown")
+
+	_, err = kit.FromCurl(`curl a -d @not-exists`)
+	s.Error(err)
+}
@@ -0,0 +1,180 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ysmood/kit/pkg/utils"
+)
+
+// Limiter limits the rate and the number of in-flight requests per host.
+// Share the same limiter between requests or sessions to make them share the limits,
+// it's safe for concurrent use.
+type Limiter struct {
+	lock        sync.Mutex
+	rate        float64
+	burst       float64
+	maxInFlight int
+	hosts       map[string]*hostLimit
+}
+
+type hostLimit struct {
+	tokens   float64
+	last     time.Time
+	inFlight chan utils.Nil
+}
+
+// NewLimiter creates a limiter, rate is the number of requests per second with a token bucket
+// of the burst size. maxInFlight is the max number of the requests that haven't finished reading
+// the response body. If the rate or maxInFlight is not greater than 0, it won't be limited.
+func NewLimiter(rate float64, burst, maxInFlight int) *Limiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &Limiter{
+		rate:        rate,
+		burst:       float64(burst),
+		maxInFlight: maxInFlight,
+		hosts:       map[string]*hostLimit{},
+	}
+}
+
+// Limit sends the request with the limiter
+func (ctx *ReqContext) Limit(l *Limiter) *ReqContext {
+	return ctx.Use(l.Middleware())
+}
+
+// Limit sends all the requests of the session with the limiter
+func (s *SessionContext) Limit(l *Limiter) *SessionContext {
+	return s.Use(l.Middleware())
+}
+
+// Middleware of the limiter, the wait time will be recorded to the Timing if Trace is enabled
+func (l *Limiter) Middleware() Middleware {
+	return func(next RoundTrip) RoundTrip {
+		return func(req *http.Request) (*http.Response, error) {
+			t := timingFrom(req.Context())
+			if t != nil {
+				t.begin("limit")
+			}
+
+			release, err := l.Wait(req.Context(), req.URL.Host)
+
+			if t != nil {
+				t.end("limit")
+			}
+
+			if err != nil {
+				return nil, err
+			}
+
+			res, err := next(req)
+			if err != nil {
+				release()
+				return nil, err
+			}
+
+			res.Body = &releaseBody{ReadCloser: res.Body, release: release}
+			return res, nil
+		}
+	}
+}
+
+// Wait blocks until the request to the host is allowed or the ctx is done,
+// call the release when the request is finished
+func (l *Limiter) Wait(ctx context.Context, host string) (release func(), err error) {
+	h := l.host(host)
+
+	release = func() {}
+	if h.inFlight != nil {
+		select {
+		case h.inFlight <- utils.Nil{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		once := sync.Once{}
+		release = func() { once.Do(func() { <-h.inFlight }) }
+	}
+
+	err = sleepCtx(ctx, l.reserve(h))
+	if err != nil {
+		l.cancel(h)
+		release()
+		return nil, err
+	}
+
+	return release, nil
+}
+
+func (l *Limiter) host(host string) *hostLimit {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	h, has := l.hosts[host]
+	if !has {
+		h = &hostLimit{tokens: l.burst, last: time.Now()}
+		if l.maxInFlight > 0 {
+			h.inFlight = make(chan utils.Nil, l.maxInFlight)
+		}
+		l.hosts[host] = h
+	}
+	return h
+}
+
+// reserve takes a token, returns how long to wait for the token
+func (l *Limiter) reserve(h *hostLimit) time.Duration {
+	if l.rate <= 0 {
+		return 0
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := time.Now()
+	h.tokens += now.Sub(h.last).Seconds() * l.rate
+	if h.tokens > l.burst {
+		h.tokens = l.burst
+	}
+	h.last = now
+
+	h.tokens--
+	if h.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-h.tokens / l.rate * float64(time.Second))
+}
+
+// cancel returns the reserved token
+func (l *Limiter) cancel(h *hostLimit) {
+	if l.rate <= 0 {
+		return
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	h.tokens++
+}
+
+type releaseBody struct {
+	io.ReadCloser
+	release func()
+}
+
+// release the slot once the body is fully read, even if the body is not closed
+func (b *releaseBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err == io.EOF {
+		b.release()
+	}
+	return n, err
+}
+
+func (b *releaseBody) Close() error {
+	defer b.release()
+	return b.ReadCloser.Close()
+}
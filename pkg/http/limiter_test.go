@@ -0,0 +1,150 @@
+package http_test
+
+import (
+	"context"
+	"io/ioutil"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ysmood/kit"
+)
+
+func (s *RequestSuite) TestLimiterRate() {
+	path, url := s.path()
+
+	s.router.GET(path, func(c kit.GinContext) {
+		c.String(200, "ok")
+	})
+
+	l := kit.NewLimiter(20, 1, 0)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		s.Equal("ok", kit.Req(url).Limit(l).MustString())
+	}
+	s.GreaterOrEqual(int64(time.Since(start)), int64(90*time.Millisecond))
+
+	c := kit.Req(url).Limit(l).Trace(false)
+	c.MustDo()
+	s.Greater(int64(c.Timing().Limit), int64(30*time.Millisecond))
+	s.Regexp(`limit +\|=+ *\|`, c.Timing().Waterfall())
+}
+
+func (s *RequestSuite) TestLimiterInFlight() {
+	path, url := s.path()
+
+	var count, max int32
+	s.router.GET(path, func(c kit.GinContext) {
+		n := atomic.AddInt32(&count, 1)
+		for {
+			m := atomic.LoadInt32(&max)
+			if n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&count, -1)
+		c.String(200, "ok")
+	})
+
+	l := kit.NewLimiter(0, 0, 2)
+	session := kit.Session().Limit(l)
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.Equal("ok", session.Req(url).MustString())
+		}()
+	}
+	wg.Wait()
+
+	s.Equal(int32(2), max)
+}
+
+func (s *RequestSuite) TestLimiterDo() {
+	path, url := s.path()
+
+	s.router.GET(path, func(c kit.GinContext) {
+		c.String(200, "ok")
+	})
+
+	l := kit.NewLimiter(0, 0, 2)
+
+	// fail instead of blocking forever if the slots run out
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	// the Do reads the body, so the slots won't run out
+	for i := 0; i < 5; i++ {
+		kit.Req(url).Context(ctx).Limit(l).MustDo()
+	}
+
+	// the slot is released once the body is fully read
+	for i := 0; i < 5; i++ {
+		res := kit.Req(url).Context(ctx).Limit(l).MustResponse()
+		_, _ = ioutil.ReadAll(res.Body)
+	}
+}
+
+func (s *RequestSuite) TestLimiterPerHost() {
+	l := kit.NewLimiter(1, 1, 0)
+
+	release, err := l.Wait(context.Background(), "a")
+	s.Nil(err)
+	release()
+
+	start := time.Now()
+	release, err = l.Wait(context.Background(), "b")
+	s.Nil(err)
+	release()
+	s.Less(int64(time.Since(start)), int64(100*time.Millisecond))
+}
+
+func (s *RequestSuite) TestLimiterContext() {
+	l := kit.NewLimiter(0, 0, 1)
+
+	release, err := l.Wait(context.Background(), "a")
+	s.Nil(err)
+
+	c, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = l.Wait(c, "a")
+	s.Equal(context.DeadlineExceeded, err)
+
+	release()
+	release()
+	release, err = l.Wait(context.Background(), "a")
+	s.Nil(err)
+	release()
+
+	l = kit.NewLimiter(1, 1, 0)
+	_, _ = l.Wait(context.Background(), "a")
+	c, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = l.Wait(c, "a")
+	s.Equal(context.DeadlineExceeded, err)
+}
+
+func (s *RequestSuite) TestLimiterErr() {
+	_, url := s.path()
+
+	l := kit.NewLimiter(0, 0, 1)
+	c, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := kit.Req(url).Context(c).Limit(l).Do()
+	s.Error(err)
+
+	release, err := l.Wait(context.Background(), "x")
+	s.Nil(err)
+	release()
+
+	err = kit.Req("http://127.0.0.1:1").Limit(l).Do()
+	s.Error(err)
+	release, err = l.Wait(context.Background(), "127.0.0.1:1")
+	s.Nil(err)
+	release()
+}
@@ -0,0 +1,145 @@
+package http
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/ysmood/kit/pkg/utils"
+)
+
+// RoundTrip sends the request and gets the response
+type RoundTrip func(*http.Request) (*http.Response, error)
+
+// Middleware wraps the next RoundTrip, such as to sign, trace or log the request.
+// A middleware should clone the request before modifying it.
+type Middleware func(next RoundTrip) RoundTrip
+
+// DefaultMiddlewares are applied to every request before the ones added by ReqContext.Use
+var DefaultMiddlewares = []Middleware{}
+
+// Use appends middlewares to the request, the first one will be the outermost
+func (ctx *ReqContext) Use(list ...Middleware) *ReqContext {
+	ctx.middlewares = append(ctx.middlewares, list...)
+	return ctx
+}
+
+func (ctx *ReqContext) roundTrip() RoundTrip {
+	rt := RoundTrip(ctx.client.Do)
+
+	list := []Middleware{}
+	if !ctx.bare {
+		list = append(list, DefaultMiddlewares...)
+	}
+	list = append(list, ctx.middlewares...)
+	for i := len(list) - 1; i >= 0; i-- {
+		rt = list[i](rt)
+	}
+
+	return rt
+}
+
+// sets the request header if it's not set yet
+func headerMiddleware(key string, value func() string) Middleware {
+	return func(next RoundTrip) RoundTrip {
+		return func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get(key) == "" {
+				req = req.Clone(req.Context())
+				req.Header.Set(key, value())
+			}
+			return next(req)
+		}
+	}
+}
+
+// BearerAuth sets the Authorization header with the token
+func BearerAuth(token string) Middleware {
+	return headerMiddleware("Authorization", func() string { return "Bearer " + token })
+}
+
+// BasicAuth sets the Authorization header with the username and password
+func BasicAuth(username, password string) Middleware {
+	return headerMiddleware("Authorization", func() string {
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+	})
+}
+
+// RequestID sets the X-Request-Id header with a random id for each request
+func RequestID() Middleware {
+	return headerMiddleware("X-Request-Id", func() string { return utils.RandString(8) })
+}
+
+// the max size of the body that the Logger prints
+const logBodyLimit = 4 * 1024
+
+// Logger logs the request and response via utils.Log in the same format as MustCurl.
+// To keep the bodies streamed, only the first 4KB of each body will be printed, and the bodies
+// that have unknown length, multipart bodies and event streams won't be printed.
+func Logger() Middleware {
+	return func(next RoundTrip) RoundTrip {
+		return func(req *http.Request) (*http.Response, error) {
+			var reqBody []byte
+			var err error
+			if isLoggable(req.ContentLength, req.Header) {
+				req = req.Clone(req.Context())
+				reqBody, req.Body, err = peekPrefix(req.Body)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			res, err := next(req)
+			if err != nil {
+				utils.Log(formatCurl(req, curlData(string(reqBody))), "\n\n"+utils.C(err, "red"))
+				return nil, err
+			}
+
+			var resBody []byte
+			if isLoggable(res.ContentLength, res.Header) {
+				resBody, res.Body, err = peekPrefix(res.Body)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			utils.Log(formatCurl(req, curlData(string(reqBody))) + "\n\n" + formatResponse(res, resBody))
+
+			return res, nil
+		}
+	}
+}
+
+func isLoggable(contentLength int64, header http.Header) bool {
+	t := header.Get("Content-Type")
+	return contentLength > 0 &&
+		!strings.HasPrefix(t, "multipart/") &&
+		!strings.HasPrefix(t, "text/event-stream")
+}
+
+// peekPrefix reads at most logBodyLimit bytes of the body, then puts them back in front of the rest of the body.
+// If the body is longer than the limit, "..." will be appended to the returned prefix.
+func peekPrefix(body io.ReadCloser) ([]byte, io.ReadCloser, error) {
+	if body == nil || body == http.NoBody {
+		return nil, body, nil
+	}
+
+	prefix, err := ioutil.ReadAll(io.LimitReader(body, logBodyLimit+1))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rest := &readCloser{io.MultiReader(bytes.NewReader(prefix), body), body}
+
+	if len(prefix) > logBodyLimit {
+		return append(prefix[:logBodyLimit:logBodyLimit], "..."...), rest, nil
+	}
+	return prefix, rest, nil
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
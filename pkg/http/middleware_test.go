@@ -0,0 +1,132 @@
+package http_test
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ysmood/kit"
+	khttp "github.com/ysmood/kit/pkg/http"
+	"github.com/ysmood/kit/pkg/utils"
+)
+
+func (s *RequestSuite) TestMiddleware() {
+	path, url := s.path()
+
+	s.router.GET(path, func(c kit.GinContext) {
+		c.String(200, c.GetHeader("a")+c.GetHeader("b"))
+	})
+
+	set := func(k, v string) kit.Middleware {
+		return func(next kit.RoundTrip) kit.RoundTrip {
+			return func(req *http.Request) (*http.Response, error) {
+				req = req.Clone(req.Context())
+				req.Header.Set(k, req.Header.Get(k)+v)
+				return next(req)
+			}
+		}
+	}
+
+	c := kit.Req(url).Use(set("a", "1"), set("a", "2"), set("b", "3"))
+
+	s.Equal("123", c.MustString())
+}
+
+func (s *RequestSuite) TestDefaultMiddlewares() {
+	// the DefaultMiddlewares is shared by all the requests of the package, restore it after the test
+	old := khttp.DefaultMiddlewares
+	defer func() { khttp.DefaultMiddlewares = old }()
+
+	server, url := s.server()
+	server.Engine.GET("/", func(c kit.GinContext) {
+		c.String(200, c.GetHeader("X-Request-Id"))
+	})
+
+	khttp.DefaultMiddlewares = []kit.Middleware{kit.RequestID()}
+
+	s.Len(kit.Req(url).MustString(), 16)
+	s.Equal("id", kit.Req(url).Header("X-Request-Id", "id").MustString())
+}
+
+func (s *RequestSuite) TestBearerAuth() {
+	path, url := s.path()
+
+	s.router.GET(path, func(c kit.GinContext) {
+		c.String(200, c.GetHeader("Authorization"))
+	})
+
+	s.Equal("Bearer token", kit.Req(url).Use(kit.BearerAuth("token")).MustString())
+	s.Equal("x", kit.Req(url).Header("Authorization", "x").Use(kit.BearerAuth("token")).MustString())
+}
+
+func (s *RequestSuite) TestBasicAuth() {
+	path, url := s.path()
+
+	s.router.GET(path, func(c kit.GinContext) {
+		u, p, _ := c.Request.BasicAuth()
+		c.String(200, u+":"+p)
+	})
+
+	s.Equal("user:pass", kit.Req(url).Use(kit.BasicAuth("user", "pass")).MustString())
+	s.Equal("a:b", kit.Req(url).Use(kit.BasicAuth("a", "b"), kit.BasicAuth("user", "pass")).MustString())
+}
+
+func (s *RequestSuite) TestLogger() {
+	path, url := s.path()
+
+	s.router.POST(path, func(c kit.GinContext) {
+		data, _ := c.GetRawData()
+		c.Header("Content-Length", strconv.Itoa(len(data)))
+		c.Data(200, "", data)
+	})
+
+	c := kit.Req(url).Post().StringBody("ok").Use(kit.Logger())
+	s.Equal("ok", c.MustString())
+
+	s.Error(kit.Req("http://127.0.0.1:1").Use(kit.Logger()).Do())
+}
+
+func (s *RequestSuite) TestLoggerStream() {
+	stdout := utils.Stdout
+	defer func() { utils.Stdout = stdout }()
+	out := bytes.NewBuffer(nil)
+	utils.Stdout = out
+
+	path, url := s.path()
+
+	release := make(chan kit.Nil)
+	s.router.GET(path, func(c kit.GinContext) {
+		c.Header("Content-Type", "text/event-stream")
+		_, _ = c.Writer.WriteString("data: 1\n\n")
+		c.Writer.Flush()
+		<-release
+	})
+
+	kit.E(kit.Req(url).Use(kit.Logger()).SSE(func(e *kit.SSEEvent) error {
+		s.Equal("1", e.Data)
+		close(release)
+		return nil
+	}))
+	s.NotContains(out.String(), "data: 1")
+}
+
+func (s *RequestSuite) TestLoggerLimit() {
+	stdout := utils.Stdout
+	defer func() { utils.Stdout = stdout }()
+	out := bytes.NewBuffer(nil)
+	utils.Stdout = out
+
+	path, url := s.path()
+
+	body := strings.Repeat("a", 5000)
+	s.router.POST(path, func(c kit.GinContext) {
+		data, _ := c.GetRawData()
+		c.Header("Content-Length", strconv.Itoa(len(data)))
+		c.Data(200, "", data)
+	})
+
+	s.Equal(body, kit.Req(url).Post().StringBody(body).Use(kit.Logger()).MustString())
+	s.Contains(out.String(), "-d "+strings.Repeat("a", 4096)+"...")
+	s.Contains(out.String(), "\n\n"+strings.Repeat("a", 4096)+"...")
+}
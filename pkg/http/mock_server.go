@@ -0,0 +1,453 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ysmood/kit/pkg/utils"
+)
+
+// MockServerContext is a server that responds the requests with the declared mocks
+type MockServerContext struct {
+	*ServerContext
+
+	// URL of the server, such as http://127.0.0.1:3000
+	URL string
+
+	lock      sync.Mutex
+	mocks     []*Mock
+	requests  []*MockRequest
+	unmatched []*MockRequest
+}
+
+// MockRequest is a request received by the mock server
+type MockRequest struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Header http.Header
+	Body   []byte
+}
+
+// Mock is the expectation of the requests and the canned response
+type Mock struct {
+	lock sync.Mutex
+
+	method string
+	path   string
+	query  url.Values
+	header http.Header
+	body   func([]byte) bool
+
+	times int
+	calls int
+
+	status      int
+	resHeader   http.Header
+	render      func(req *MockRequest, params map[string]string) ([]byte, error)
+	contentType string
+	delay       time.Duration
+	fail        bool
+}
+
+// MockServer listens to a random local port and starts to serve, the requests that don't
+// match any gin route will be handled by the mocks
+func MockServer() (*MockServerContext, error) {
+	server, err := Server("127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	m := &MockServerContext{
+		ServerContext: server,
+		URL:           "http://" + server.Listener.Addr().String(),
+	}
+	server.Engine.NoRoute(m.handle)
+
+	go func() { _ = server.Do() }()
+	<-server.Ready()
+
+	return m, nil
+}
+
+// MustMockServer ...
+func MustMockServer() *MockServerContext {
+	return utils.E(MockServer())[0].(*MockServerContext)
+}
+
+// On declares a mock for the method and path, an empty method matches any method.
+// The path segment that starts with ":" matches any segment, it can be used in ReplyTemplate
+// as {{.params.name}}, such as "/users/:id".
+// The mocks are matched in the order of declaration.
+func (m *MockServerContext) On(method, path string) *Mock {
+	mock := &Mock{
+		method:    strings.ToUpper(method),
+		path:      path,
+		query:     url.Values{},
+		header:    http.Header{},
+		times:     -1,
+		status:    http.StatusOK,
+		resHeader: http.Header{},
+		render: func(*MockRequest, map[string]string) ([]byte, error) {
+			return nil, nil
+		},
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.mocks = append(m.mocks, mock)
+	return mock
+}
+
+// Requests returns all the requests received, including the unmatched ones
+func (m *MockServerContext) Requests() []*MockRequest {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return append([]*MockRequest{}, m.requests...)
+}
+
+// Verify checks if all the mocks are called as expected and there's no unmatched request.
+// A mock is expected to be called at least once unless Times or AnyTimes is set.
+func (m *MockServerContext) Verify() error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	errs := []string{}
+	for _, mock := range m.mocks {
+		if err := mock.verify(); err != "" {
+			errs = append(errs, err)
+		}
+	}
+	for _, req := range m.unmatched {
+		errs = append(errs, fmt.Sprintf("unexpected request %s %s", req.Method, req.Path))
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errors.New(strings.Join(errs, "\n"))
+}
+
+// MustVerify ...
+func (m *MockServerContext) MustVerify() {
+	utils.E(m.Verify())
+}
+
+// Close shutdowns the server without waiting for the active connections
+func (m *MockServerContext) Close() {
+	c, cancel := context.WithCancel(context.Background())
+	cancel()
+	_ = m.Shutdown(c)
+}
+
+// Query matches the query params of the request, example Query(k, v, k, v ...)
+func (mock *Mock) Query(params ...string) *Mock {
+	for i := 0; i < len(params)-1; i += 2 {
+		mock.query.Add(params[i], params[i+1])
+	}
+	return mock
+}
+
+// Header matches the headers of the request, example Header(k, v, k, v ...)
+func (mock *Mock) Header(params ...string) *Mock {
+	for i := 0; i < len(params)-1; i += 2 {
+		mock.header.Add(params[i], params[i+1])
+	}
+	return mock
+}
+
+// Body matches the request body exactly
+func (mock *Mock) Body(s string) *Mock {
+	return mock.BodyMatch(func(b []byte) bool { return string(b) == s })
+}
+
+// JSONBody matches the request body if it's the same json as the v, the v can also be a json string
+func (mock *Mock) JSONBody(v interface{}) *Mock {
+	expected, err := normalizeJSON(v)
+	utils.E(err)
+
+	return mock.BodyMatch(func(b []byte) bool {
+		var actual interface{}
+		if json.Unmarshal(b, &actual) != nil {
+			return false
+		}
+		return reflect.DeepEqual(expected, actual)
+	})
+}
+
+// BodyMatch matches the request body with the fn
+func (mock *Mock) BodyMatch(fn func([]byte) bool) *Mock {
+	mock.body = fn
+	return mock
+}
+
+// Times expects the mock to be called n times, after that it won't match any request
+func (mock *Mock) Times(n int) *Mock {
+	mock.times = n
+	return mock
+}
+
+// Once is the same as Times(1)
+func (mock *Mock) Once() *Mock {
+	return mock.Times(1)
+}
+
+// AnyTimes makes Verify ignore the call count of the mock
+func (mock *Mock) AnyTimes() *Mock {
+	mock.times = -2
+	return mock
+}
+
+// Calls returns the number of the matched requests
+func (mock *Mock) Calls() int {
+	mock.lock.Lock()
+	defer mock.lock.Unlock()
+
+	return mock.calls
+}
+
+// ReplyHeader sets the response headers, example ReplyHeader(k, v, k, v ...)
+func (mock *Mock) ReplyHeader(params ...string) *Mock {
+	for i := 0; i < len(params)-1; i += 2 {
+		mock.resHeader.Add(params[i], params[i+1])
+	}
+	return mock
+}
+
+// Reply responds the status code and the body
+func (mock *Mock) Reply(status int, body string) *Mock {
+	mock.status = status
+	mock.render = func(*MockRequest, map[string]string) ([]byte, error) {
+		return []byte(body), nil
+	}
+	return mock
+}
+
+// ReplyTemplate responds the body rendered by utils.S, the variables are method, path,
+// params, query, header and body, such as "{{.params.id}} {{.query.Get "q"}}"
+func (mock *Mock) ReplyTemplate(status int, tpl string) *Mock {
+	mock.status = status
+	mock.render = func(req *MockRequest, params map[string]string) (b []byte, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("%v", r)
+			}
+		}()
+
+		return []byte(utils.S(tpl,
+			"method", req.Method,
+			"path", req.Path,
+			"params", params,
+			"query", req.Query,
+			"header", req.Header,
+			"body", string(req.Body),
+		)), nil
+	}
+	return mock
+}
+
+// ReplyJSON responds the v as json
+func (mock *Mock) ReplyJSON(status int, v interface{}) *Mock {
+	mock.status = status
+	mock.contentType = "application/json; charset=utf-8"
+	mock.render = func(*MockRequest, map[string]string) ([]byte, error) {
+		return json.Marshal(v)
+	}
+	return mock
+}
+
+// Delay waits for the d before responding
+func (mock *Mock) Delay(d time.Duration) *Mock {
+	mock.delay = d
+	return mock
+}
+
+// Fail closes the connection without responding, to simulate the network failure
+func (mock *Mock) Fail() *Mock {
+	mock.fail = true
+	return mock
+}
+
+func (m *MockServerContext) handle(c GinContext) {
+	body, _ := ioutil.ReadAll(c.Request.Body)
+	req := &MockRequest{
+		Method: c.Request.Method,
+		Path:   c.Request.URL.Path,
+		Query:  c.Request.URL.Query(),
+		Header: c.Request.Header.Clone(),
+		Body:   body,
+	}
+
+	m.lock.Lock()
+	m.requests = append(m.requests, req)
+	var mock *Mock
+	var params map[string]string
+	for _, item := range m.mocks {
+		if p, ok := item.match(req); ok {
+			mock, params = item, p
+			break
+		}
+	}
+	if mock == nil {
+		m.unmatched = append(m.unmatched, req)
+	}
+	m.lock.Unlock()
+
+	if mock == nil {
+		c.String(http.StatusNotFound, "no mock matches %s %s", req.Method, req.Path)
+		return
+	}
+
+	mock.respond(c, req, params)
+}
+
+// match checks the request and increases the calls if matched
+func (mock *Mock) match(req *MockRequest) (map[string]string, bool) {
+	mock.lock.Lock()
+	defer mock.lock.Unlock()
+
+	if mock.times >= 0 && mock.calls >= mock.times {
+		return nil, false
+	}
+	if mock.method != "" && mock.method != req.Method {
+		return nil, false
+	}
+
+	params, ok := matchPath(mock.path, req.Path)
+	if !ok {
+		return nil, false
+	}
+
+	for k, list := range mock.query {
+		for _, v := range list {
+			if !hasValue(req.Query[k], v) {
+				return nil, false
+			}
+		}
+	}
+	for k, list := range mock.header {
+		for _, v := range list {
+			if !hasValue(req.Header.Values(k), v) {
+				return nil, false
+			}
+		}
+	}
+	if mock.body != nil && !mock.body(req.Body) {
+		return nil, false
+	}
+
+	mock.calls++
+	return params, true
+}
+
+func (mock *Mock) respond(c GinContext, req *MockRequest, params map[string]string) {
+	if sleepCtx(c.Request.Context(), mock.delay) != nil {
+		return
+	}
+
+	if mock.fail {
+		conn, _, err := c.Writer.Hijack()
+		if err == nil {
+			_ = conn.Close()
+		}
+		return
+	}
+
+	body, err := mock.render(req, params)
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	for k, list := range mock.resHeader {
+		for _, v := range list {
+			c.Writer.Header().Add(k, v)
+		}
+	}
+
+	contentType := mock.resHeader.Get("Content-Type")
+	if contentType == "" {
+		contentType = mock.contentType
+	}
+	if contentType == "" {
+		contentType = http.DetectContentType(body)
+	}
+	c.Data(mock.status, contentType, body)
+}
+
+func (mock *Mock) verify() string {
+	mock.lock.Lock()
+	defer mock.lock.Unlock()
+
+	name := strings.TrimSpace(mock.method + " " + mock.path)
+
+	switch {
+	case mock.times == -1 && mock.calls == 0:
+		return fmt.Sprintf("mock %s: expected to be called, got 0 calls", name)
+	case mock.times >= 0 && mock.calls != mock.times:
+		return fmt.Sprintf("mock %s: expected %d calls, got %d", name, mock.times, mock.calls)
+	}
+	return ""
+}
+
+// matchPath matches the path with the pattern, the segment starts with ":" matches any segment
+func matchPath(pattern, p string) (map[string]string, bool) {
+	params := map[string]string{}
+
+	expected := strings.Split(strings.Trim(pattern, "/"), "/")
+	actual := strings.Split(strings.Trim(p, "/"), "/")
+	if len(expected) != len(actual) {
+		return nil, false
+	}
+
+	for i, seg := range expected {
+		if strings.HasPrefix(seg, ":") {
+			params[seg[1:]] = actual[i]
+		} else if seg != actual[i] {
+			return nil, false
+		}
+	}
+
+	return params, true
+}
+
+func hasValue(list []string, v string) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeJSON(v interface{}) (interface{}, error) {
+	var b []byte
+	switch t := v.(type) {
+	case string:
+		b = []byte(t)
+	case []byte:
+		b = t
+	default:
+		var err error
+		b, err = json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var n interface{}
+	err := json.NewDecoder(bytes.NewReader(b)).Decode(&n)
+	return n, err
+}
@@ -0,0 +1,87 @@
+package http_test
+
+import (
+	"time"
+
+	"github.com/ysmood/kit"
+)
+
+func (s *RequestSuite) TestMockServer() {
+	m := kit.MustMockServer()
+	defer m.Close()
+
+	m.On("GET", "/users/:id").Query("q", "a").Header("X-Test", "ok").
+		ReplyTemplate(200, `{{.method}} {{.params.id}} {{.query.Get "q"}} {{.header.Get "X-Test"}}`)
+	m.On("POST", "/users").JSONBody(`{"name": "jack", "age": 10}`).ReplyJSON(201, map[string]int{"id": 1})
+	m.On("POST", "/echo").Body("hi").ReplyHeader("X-Res", "ok").Reply(200, "hi").Once()
+	m.On("", "/any").AnyTimes()
+
+	s.Equal("GET 1 a ok", kit.Req(m.URL+"/users/1").Query("q", "a").Header("X-Test", "ok").MustString())
+
+	req := kit.Req(m.URL + "/users").Post().JSONBody(map[string]interface{}{"age": 10, "name": "jack"})
+	s.Equal(`{"id":1}`, req.MustString())
+	s.Equal(201, req.MustResponse().StatusCode)
+	s.Equal("application/json; charset=utf-8", req.MustResponse().Header.Get("Content-Type"))
+
+	req = kit.Req(m.URL + "/echo").Post().StringBody("hi")
+	s.Equal("hi", req.MustString())
+	s.Equal("ok", req.MustResponse().Header.Get("X-Res"))
+
+	m.MustVerify()
+
+	// exhausted
+	s.Equal(404, kit.Req(m.URL+"/echo").Post().StringBody("hi").MustResponse().StatusCode)
+	s.Equal(404, kit.Req(m.URL+"/users/1").Query("q", "b").Header("X-Test", "ok").MustResponse().StatusCode)
+
+	s.EqualError(m.Verify(), "unexpected request POST /echo\nunexpected request GET /users/1")
+
+	list := m.Requests()
+	s.Len(list, 5)
+	s.Equal("POST", list[1].Method)
+	s.Equal("/users", list[1].Path)
+	s.JSONEq(`{"name": "jack", "age": 10}`, string(list[1].Body))
+	s.Equal("b", list[4].Query.Get("q"))
+	s.Equal("ok", list[4].Header.Get("X-Test"))
+}
+
+func (s *RequestSuite) TestMockServerVerify() {
+	m := kit.MustMockServer()
+	defer m.Close()
+
+	a := m.On("GET", "/a")
+	m.On("GET", "/b").Times(2).Reply(200, "b")
+
+	s.Equal("b", kit.Req(m.URL+"/b").MustString())
+	s.Equal(1, m.On("GET", "/c").Calls()+1)
+
+	s.EqualError(m.Verify(), "mock GET /a: expected to be called, got 0 calls\n"+
+		"mock GET /b: expected 2 calls, got 1\n"+
+		"mock GET /c: expected to be called, got 0 calls")
+
+	s.Equal("", kit.Req(m.URL+"/a").MustString())
+	s.Equal(1, a.Calls())
+}
+
+func (s *RequestSuite) TestMockServerInjection() {
+	m := kit.MustMockServer()
+	defer m.Close()
+
+	m.On("GET", "/").Times(2).Fail()
+	m.On("GET", "/").Delay(50*time.Millisecond).Reply(200, "ok")
+	m.On("GET", "/tpl").ReplyTemplate(200, "{{.method")
+
+	s.Error(kit.Req(m.URL).Do())
+
+	c := kit.Req(m.URL).Retry(kit.CountSleeper(3), kit.RetryOnFailure)
+	start := time.Now()
+	s.Equal("ok", c.MustString())
+	s.Equal(2, c.Attempts())
+	s.GreaterOrEqual(int64(time.Since(start)), int64(50*time.Millisecond))
+
+	s.Error(kit.Req(m.URL).Timeout(10 * time.Millisecond).Do())
+
+	res := kit.Req(m.URL + "/tpl").MustResponse()
+	s.Equal(500, res.StatusCode)
+
+	s.Nil(m.Verify())
+}
@@ -0,0 +1,95 @@
+package http
+
+import (
+	"io"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/alessio/shellescape"
+)
+
+type multipartPart struct {
+	field string
+	value string
+	path  string // if it's not empty, the part is a file
+}
+
+// Multipart appends fields to the multipart form body, example Multipart(k, v, k, v ...)
+func (ctx *ReqContext) Multipart(params ...string) *ReqContext {
+	for i := 0; i < len(params); i += 2 {
+		ctx.addPart(multipartPart{field: params[i], value: params[i+1]})
+	}
+	return ctx
+}
+
+// File appends a file to the multipart form body, the file will be streamed from the disk
+// when the request is sent
+func (ctx *ReqContext) File(field, path string) *ReqContext {
+	return ctx.addPart(multipartPart{field: field, path: path})
+}
+
+func (ctx *ReqContext) addPart(p multipartPart) *ReqContext {
+	if ctx.multipartBoundary == "" {
+		ctx.multipartBoundary = multipart.NewWriter(nil).Boundary()
+		ctx.header["Content-Type"] = []string{"multipart/form-data; boundary=" + ctx.multipartBoundary}
+	}
+	ctx.multipart = append(ctx.multipart, p)
+	return ctx
+}
+
+// multipartBody streams the parts through a pipe, so that large files won't be loaded into memory
+func (ctx *ReqContext) multipartBody() io.ReadCloser {
+	r, w := io.Pipe()
+	mw := multipart.NewWriter(w)
+	_ = mw.SetBoundary(ctx.multipartBoundary)
+
+	go func() {
+		for _, p := range ctx.multipart {
+			err := writePart(mw, p)
+			if err != nil {
+				_ = w.CloseWithError(err)
+				return
+			}
+		}
+		_ = w.CloseWithError(mw.Close())
+	}()
+
+	return r
+}
+
+func writePart(mw *multipart.Writer, p multipartPart) error {
+	if p.path == "" {
+		return mw.WriteField(p.field, p.value)
+	}
+
+	f, err := os.Open(p.path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	w, err := mw.CreateFormFile(p.field, filepath.Base(p.path))
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// curlMultipart renders the parts as curl form flags
+func (ctx *ReqContext) curlMultipart() string {
+	out := ""
+	for _, p := range ctx.multipart {
+		if p.path != "" {
+			out += " \\\n  -F " + shellescape.Quote(p.field+"=@"+p.path)
+		} else if strings.HasPrefix(p.value, "@") || strings.HasPrefix(p.value, "<") {
+			out += " \\\n  --form-string " + shellescape.Quote(p.field+"="+p.value)
+		} else {
+			out += " \\\n  -F " + shellescape.Quote(p.field+"="+p.value)
+		}
+	}
+	return out
+}
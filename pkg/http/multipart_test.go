@@ -0,0 +1,96 @@
+package http_test
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/ysmood/kit"
+)
+
+func (s *RequestSuite) TestMultipart() {
+	path, url := s.path()
+
+	s.router.POST(path, func(c kit.GinContext) {
+		f, err := c.FormFile("file")
+		kit.E(err)
+		r, err := f.Open()
+		kit.E(err)
+		data, err := ioutil.ReadAll(r)
+		kit.E(err)
+
+		c.String(200, c.PostForm("a")+c.PostForm("b")+f.Filename+string(data))
+	})
+
+	p := filepath.Join("tmp", kit.RandString(8), "a.txt")
+	kit.E(kit.OutputFile(p, "file", nil))
+	defer func() { _ = kit.Remove("tmp") }()
+
+	c := kit.Req(url).Post().Multipart("a", "1").File("file", p).Multipart("b", "2")
+
+	s.Equal("12a.txtfile", c.MustString())
+}
+
+func (s *RequestSuite) TestMultipartRetry() {
+	path, url := s.path()
+
+	count := 0
+	s.router.POST(path, func(c kit.GinContext) {
+		count++
+		if count < 2 {
+			c.String(500, "")
+			return
+		}
+		c.String(200, c.PostForm("a"))
+	})
+
+	c := kit.Req(url).Post().Multipart("a", "ok").Retry(kit.CountSleeper(3), nil)
+
+	s.Equal("ok", c.MustString())
+	s.Equal(2, c.Attempts())
+}
+
+func (s *RequestSuite) TestMultipartFileErr() {
+	// the request may still reach the handler after the error, use a standalone server and
+	// wait for the handler to finish, so that it won't race with the other tests
+	server, url := s.server()
+	defer server.MustShutdown(context.Background())
+
+	server.Engine.POST("/", func(c kit.GinContext) {
+		_, _ = c.GetRawData()
+	})
+
+	err := kit.Req(url).Post().File("file", "not-exists").Do()
+	s.Contains(err.Error(), "not-exists")
+}
+
+func (s *RequestSuite) TestMultipartMustCurl() {
+	path, url := s.path()
+
+	s.router.POST(path, func(c kit.GinContext) {
+		c.String(200, c.PostForm("a"))
+	})
+
+	p := filepath.Join("tmp", kit.RandString(8), "a.txt")
+	kit.E(kit.OutputFile(p, "file", nil))
+	defer func() { _ = kit.Remove("tmp") }()
+
+	c := kit.Req(url).Post().Multipart("a", "ok", "b", "@x").File("file", p)
+
+	res, err := c.Response()
+	kit.E(err)
+
+	expected := kit.S(`curl -X POST {{.url}} \
+  -F a=ok \
+  --form-string b=@x \
+  -F file=@{{.path}}
+
+HTTP/1.1 200 OK
+Content-Length: 2
+Content-Type: text/plain; charset=utf-8
+Date: {{.date}}
+
+ok`, "url", url, "path", p, "date", res.Header.Get("Date"))
+
+	s.Equal(expected, c.MustCurl())
+}
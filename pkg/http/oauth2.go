@@ -0,0 +1,273 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	gos "github.com/ysmood/kit/pkg/os"
+	"github.com/ysmood/kit/pkg/utils"
+)
+
+// Token of OAuth2
+type Token struct {
+	AccessToken  string    `json:"access_token"`
+	TokenType    string    `json:"token_type,omitempty"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	Expiry       time.Time `json:"expiry,omitempty"`
+}
+
+// Valid returns true if the access token is not empty and won't expire in 10 seconds
+func (t *Token) Valid() bool {
+	if t == nil || t.AccessToken == "" {
+		return false
+	}
+	return t.Expiry.IsZero() || time.Now().Add(10*time.Second).Before(t.Expiry)
+}
+
+// OAuth2Error is the error response of the token endpoint
+type OAuth2Error struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+	URI         string `json:"error_uri,omitempty"`
+}
+
+// Error interface
+func (e *OAuth2Error) Error() string {
+	if e.Description == "" {
+		return "oauth2: " + e.Code
+	}
+	return fmt.Sprintf("oauth2: %s: %s", e.Code, e.Description)
+}
+
+// OAuth2Context is a token source that fetches tokens from the token endpoint with
+// the client-credentials or refresh-token flow, it's safe for concurrent use
+type OAuth2Context struct {
+	lock sync.Mutex
+
+	tokenURL     string
+	clientID     string
+	clientSecret string
+	scopes       []string
+	client       *http.Client
+	path         string
+
+	token *Token
+	err   error
+}
+
+// OAuth2 creates a token source, by default it uses the client-credentials flow
+func OAuth2(tokenURL, clientID, clientSecret string) *OAuth2Context {
+	return &OAuth2Context{
+		tokenURL:     tokenURL,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+	}
+}
+
+// Scopes sets the scopes to request
+func (o *OAuth2Context) Scopes(scopes ...string) *OAuth2Context {
+	o.scopes = scopes
+	return o
+}
+
+// Client sets the client to request the token endpoint
+func (o *OAuth2Context) Client(c *http.Client) *OAuth2Context {
+	o.client = c
+	return o
+}
+
+// SetToken sets the current token, if it has a refresh token the refresh-token flow will be used
+func (o *OAuth2Context) SetToken(t *Token) *OAuth2Context {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	o.token = t
+	return o
+}
+
+// Persist loads the token from the path if it exists, and saves each new token to the path.
+// If the file can't be loaded, the error will be returned by the Token and the requests.
+func (o *OAuth2Context) Persist(path string) *OAuth2Context {
+	o.path = path
+
+	if !gos.FileExists(path) {
+		return o
+	}
+
+	var t Token
+	err := gos.ReadJSON(path, &t)
+	if err != nil {
+		o.lock.Lock()
+		defer o.lock.Unlock()
+		o.err = fmt.Errorf("oauth2: failed to load the token file %s: %w", path, err)
+		return o
+	}
+	return o.SetToken(&t)
+}
+
+// Token returns the cached token if it's valid, or fetches a new one
+func (o *OAuth2Context) Token() (*Token, error) {
+	return o.get(context.Background(), nil)
+}
+
+// MustToken panic version of Token
+func (o *OAuth2Context) MustToken() *Token {
+	return utils.E(o.Token())[0].(*Token)
+}
+
+// Refresh fetches a new token even if the cached one is still valid
+func (o *OAuth2Context) Refresh() (*Token, error) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	if o.err != nil {
+		return nil, o.err
+	}
+
+	return o.fetch(context.Background())
+}
+
+// OAuth2 sets the Authorization header with the token of the token source,
+// if the response is 401 it will refresh the token and retry once
+func (ctx *ReqContext) OAuth2(o *OAuth2Context) *ReqContext {
+	return ctx.Use(o.Middleware())
+}
+
+// OAuth2 sets the token source for all the requests of the session
+func (s *SessionContext) OAuth2(o *OAuth2Context) *SessionContext {
+	return s.Use(o.Middleware())
+}
+
+// Middleware of the token source
+func (o *OAuth2Context) Middleware() Middleware {
+	return func(next RoundTrip) RoundTrip {
+		return func(req *http.Request) (*http.Response, error) {
+			rewindable := canRewind(req)
+
+			t, err := o.get(req.Context(), nil)
+			if err != nil {
+				return nil, err
+			}
+
+			res, err := next(o.authorize(req, t))
+			if err != nil || res.StatusCode != http.StatusUnauthorized || !rewindable {
+				return res, err
+			}
+
+			// the token may be revoked, refresh it and retry once
+			t, err = o.get(req.Context(), t)
+			if err != nil {
+				return res, nil
+			}
+
+			retry, err := rewindRequest(req)
+			if err != nil {
+				return res, nil
+			}
+			_ = res.Body.Close()
+
+			return next(o.authorize(retry, t))
+		}
+	}
+}
+
+func (o *OAuth2Context) authorize(req *http.Request, t *Token) *http.Request {
+	tokenType := t.TokenType
+	if tokenType == "" || strings.EqualFold(tokenType, "bearer") {
+		tokenType = "Bearer"
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", tokenType+" "+t.AccessToken)
+	return req
+}
+
+// get returns the cached token, if the cached one is invalid or it's the stale one, fetch a new one
+func (o *OAuth2Context) get(c context.Context, stale *Token) (*Token, error) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	if o.err != nil {
+		return nil, o.err
+	}
+
+	if o.token.Valid() && (stale == nil || o.token.AccessToken != stale.AccessToken) {
+		return o.token, nil
+	}
+
+	return o.fetch(c)
+}
+
+// fetch uses the refresh-token flow if there's a refresh token, if the refresh token is rejected
+// it falls back to the client-credentials flow
+func (o *OAuth2Context) fetch(c context.Context) (*Token, error) {
+	if o.token != nil && o.token.RefreshToken != "" {
+		t, err := o.request(c, "grant_type", "refresh_token", "refresh_token", o.token.RefreshToken)
+		var oe *OAuth2Error
+		if !errors.As(err, &oe) || oe.Code != "invalid_grant" {
+			return t, err
+		}
+		o.token = nil
+	}
+
+	return o.request(c, "grant_type", "client_credentials")
+}
+
+func (o *OAuth2Context) request(c context.Context, form ...interface{}) (*Token, error) {
+	if len(o.scopes) > 0 {
+		form = append(form, "scope", strings.Join(o.scopes, " "))
+	}
+	if o.clientSecret == "" {
+		form = append(form, "client_id", o.clientID)
+	}
+
+	req := Req(o.tokenURL).Context(c).Post().Form(form...).Header("Accept", "application/json")
+
+	// the token source may be one of the DefaultMiddlewares, don't let the token request go through it again
+	req.bare = true
+	if o.clientSecret != "" {
+		req.Use(BasicAuth(o.clientID, o.clientSecret))
+	}
+	if o.client != nil {
+		req.Client(o.client)
+	}
+
+	var res struct {
+		Token
+		ExpiresIn int64 `json:"expires_in"`
+	}
+	err := req.DecodeStatus(&res, &OAuth2Error{})
+	if err != nil {
+		return nil, err
+	}
+	if res.AccessToken == "" {
+		return nil, &OAuth2Error{Code: "invalid_response", Description: "no access_token"}
+	}
+
+	t := res.Token
+	if res.ExpiresIn > 0 {
+		t.Expiry = time.Now().Add(time.Duration(res.ExpiresIn) * time.Second)
+	}
+	if t.RefreshToken == "" && o.token != nil {
+		t.RefreshToken = o.token.RefreshToken
+	}
+
+	if o.path != "" {
+		err = gos.OutputFile(o.path, &t, &gos.OutputFileOptions{
+			DirPerm:    0700,
+			FilePerm:   0600,
+			JSONIndent: "  ",
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	o.token = &t
+	return o.token, nil
+}
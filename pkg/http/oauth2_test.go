@@ -0,0 +1,161 @@
+package http_test
+
+import (
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/ysmood/kit"
+	khttp "github.com/ysmood/kit/pkg/http"
+)
+
+func (s *RequestSuite) oauth2Server() (tokenURL, apiURL string, issued *int32) {
+	tokenPath, tokenURL := s.path()
+	apiPath, apiURL := s.path()
+
+	var count int32
+	issued = &count
+
+	s.router.POST(tokenPath, func(c kit.GinContext) {
+		id, secret, _ := c.Request.BasicAuth()
+		if id == "" {
+			id = c.PostForm("client_id")
+		}
+		if id != "id" || (secret != "" && secret != "secret") {
+			c.JSON(401, map[string]string{"error": "invalid_client", "error_description": "unknown client"})
+			return
+		}
+
+		switch c.PostForm("grant_type") {
+		case "client_credentials":
+		case "refresh_token":
+			if c.PostForm("refresh_token") != "refresh" {
+				c.JSON(400, map[string]string{"error": "invalid_grant"})
+				return
+			}
+		default:
+			c.JSON(400, map[string]string{"error": "unsupported_grant_type"})
+			return
+		}
+
+		n := atomic.AddInt32(&count, 1)
+		c.JSON(200, map[string]interface{}{
+			"access_token":  "token" + strconv.Itoa(int(n)),
+			"token_type":    "bearer",
+			"expires_in":    3600,
+			"refresh_token": "refresh",
+			"scope":         c.PostForm("scope"),
+		})
+	})
+
+	s.router.GET(apiPath, func(c kit.GinContext) {
+		// only the latest token is valid
+		if c.GetHeader("Authorization") != "Bearer token"+strconv.Itoa(int(atomic.LoadInt32(&count))) {
+			c.Status(401)
+			return
+		}
+		c.String(200, "ok")
+	})
+
+	return tokenURL, apiURL, issued
+}
+
+func (s *RequestSuite) TestOAuth2ClientCredentials() {
+	tokenURL, apiURL, issued := s.oauth2Server()
+
+	o := kit.OAuth2(tokenURL, "id", "secret").Scopes("a", "b")
+
+	s.Equal("ok", kit.Req(apiURL).OAuth2(o).MustString())
+	s.Equal("ok", kit.Req(apiURL).OAuth2(o).MustString())
+	s.Equal(int32(1), *issued)
+
+	t := o.MustToken()
+	s.Equal("token1", t.AccessToken)
+	s.Equal("refresh", t.RefreshToken)
+	s.True(t.Valid())
+	s.WithinDuration(time.Now().Add(time.Hour), t.Expiry, time.Minute)
+}
+
+func (s *RequestSuite) TestOAuth2RetryOn401() {
+	tokenURL, apiURL, issued := s.oauth2Server()
+
+	o := kit.OAuth2(tokenURL, "id", "")
+	session := kit.Session().OAuth2(o)
+
+	s.Equal("ok", session.Req(apiURL).MustString())
+
+	// another client takes a new token, so the cached one is revoked
+	_, err := kit.OAuth2(tokenURL, "id", "secret").Token()
+	s.Nil(err)
+
+	s.Equal("ok", session.Req(apiURL).MustString())
+	s.Equal(int32(3), *issued)
+	s.Equal("token3", o.MustToken().AccessToken)
+}
+
+func (s *RequestSuite) TestOAuth2Refresh() {
+	tokenURL, _, _ := s.oauth2Server()
+
+	o := kit.OAuth2(tokenURL, "id", "secret").SetToken(&kit.Token{
+		AccessToken:  "old",
+		RefreshToken: "refresh",
+		Expiry:       time.Now().Add(-time.Second),
+	})
+	s.Equal("token1", o.MustToken().AccessToken)
+
+	t, err := o.Refresh()
+	s.Nil(err)
+	s.Equal("token2", t.AccessToken)
+
+	// the rejected refresh token falls back to the client-credentials flow
+	o.SetToken(&kit.Token{RefreshToken: "wrong"})
+	s.Equal("token3", o.MustToken().AccessToken)
+}
+
+func (s *RequestSuite) TestOAuth2DefaultMiddlewares() {
+	tokenURL, apiURL, _ := s.oauth2Server()
+
+	old := khttp.DefaultMiddlewares
+	defer func() { khttp.DefaultMiddlewares = old }()
+
+	// the token request shouldn't go through the token source itself
+	o := kit.OAuth2(tokenURL, "id", "secret")
+	khttp.DefaultMiddlewares = []kit.Middleware{o.Middleware()}
+
+	s.Equal("ok", kit.Req(apiURL).MustString())
+}
+
+func (s *RequestSuite) TestOAuth2Persist() {
+	defer func() { _ = kit.Remove("tmp") }()
+
+	tokenURL, _, _ := s.oauth2Server()
+
+	o := kit.OAuth2(tokenURL, "id", "secret").Persist("tmp/token.json")
+	s.Equal("token1", o.MustToken().AccessToken)
+
+	o = kit.OAuth2(tokenURL, "id", "secret").Persist("tmp/token.json")
+	s.Equal("token1", o.MustToken().AccessToken)
+
+	kit.E(kit.OutputFile("tmp/token.json", "{", nil))
+	o = kit.OAuth2(tokenURL, "id", "secret").Persist("tmp/token.json")
+	_, err := o.Token()
+	s.Contains(err.Error(), "failed to load the token file tmp/token.json")
+	_, err = o.Refresh()
+	s.Error(err)
+}
+
+func (s *RequestSuite) TestOAuth2Err() {
+	tokenURL, apiURL, _ := s.oauth2Server()
+
+	o := kit.OAuth2(tokenURL, "id", "wrong")
+	_, err := o.Token()
+	s.EqualError(err, "oauth2: invalid_client: unknown client")
+
+	s.Error(kit.Req(apiURL).OAuth2(o).Do())
+
+	_, url := s.path()
+	_, err = kit.OAuth2(url, "id", "secret").Token()
+	s.Error(err)
+
+	s.False((*kit.Token)(nil).Valid())
+}
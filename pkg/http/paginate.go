@@ -0,0 +1,297 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/ysmood/kit/pkg/utils"
+)
+
+// PaginateContext walks through the pages of a paginated api
+type PaginateContext struct {
+	req  *ReqContext
+	next func(req *ReqContext, page utils.JSONResult) (string, error)
+	max  int
+	err  error
+}
+
+// Paginate creates a paginator that uses the request as the first page,
+// by default it follows the "Link: <url>; rel=next" header.
+// The body set by Body will only be resent to the next pages if it's an io.Seeker.
+func (ctx *ReqContext) Paginate() *PaginateContext {
+	p := &PaginateContext{req: ctx}
+	return p.Link()
+}
+
+// Link follows the next url of the RFC 5988 Link header, stops when there's no next link
+func (p *PaginateContext) Link() *PaginateContext {
+	p.next = func(req *ReqContext, _ utils.JSONResult) (string, error) {
+		res, err := req.Response()
+		if err != nil {
+			return "", err
+		}
+
+		next := nextLink(res.Header.Values("Link"))
+		if next == "" {
+			return "", nil
+		}
+
+		u, err := res.Request.URL.Parse(next)
+		if err != nil {
+			return "", err
+		}
+		return u.String(), nil
+	}
+	return p
+}
+
+// Cursor gets the cursor from the page by the gjson path and sets it as the query param of the next page,
+// stops when the cursor is empty
+func (p *PaginateContext) Cursor(path, param string) *PaginateContext {
+	p.next = func(req *ReqContext, page utils.JSONResult) (string, error) {
+		cursor := page.Get(path).String()
+		if cursor == "" {
+			return "", nil
+		}
+		return setQuery(req.url, param, cursor)
+	}
+	return p
+}
+
+// Page increases the query param by one for each page, the first page is the start if the param is not set.
+// Stops when the array found by the gjson path of the items is empty, use "" as the path if the page itself is the array.
+func (p *PaginateContext) Page(param string, start int, items string) *PaginateContext {
+	p.next = func(req *ReqContext, page utils.JSONResult) (string, error) {
+		if countItems(page, items) == 0 {
+			return "", nil
+		}
+		n, err := queryInt(req.url, param, start)
+		if err != nil {
+			return "", err
+		}
+		return setQuery(req.url, param, strconv.Itoa(n+1))
+	}
+	return p
+}
+
+// Offset increases the query param by the number of the items on each page.
+// Stops when the array found by the gjson path of the items is empty, use "" as the path if the page itself is the array.
+func (p *PaginateContext) Offset(param string, items string) *PaginateContext {
+	p.next = func(req *ReqContext, page utils.JSONResult) (string, error) {
+		count := countItems(page, items)
+		if count == 0 {
+			return "", nil
+		}
+		n, err := queryInt(req.url, param, 0)
+		if err != nil {
+			return "", err
+		}
+		return setQuery(req.url, param, strconv.Itoa(n+count))
+	}
+	return p
+}
+
+// Max limits the number of pages to fetch, 0 means no limit
+func (p *PaginateContext) Max(n int) *PaginateContext {
+	p.max = n
+	return p
+}
+
+// Each calls the fn with each page, return false in the fn to stop
+func (p *PaginateContext) Each(fn func(page utils.JSONResult) bool) error {
+	return p.each(p.req, fn)
+}
+
+func (p *PaginateContext) each(req *ReqContext, fn func(page utils.JSONResult) bool) error {
+	for i := 0; p.max <= 0 || i < p.max; i++ {
+		page, err := req.JSON()
+		if err != nil {
+			return err
+		}
+
+		if !fn(page) {
+			return nil
+		}
+
+		u, err := p.next(req, page)
+		if err != nil || u == "" {
+			return err
+		}
+
+		req = req.clone(u)
+	}
+
+	return nil
+}
+
+// MustEach panic version of Each
+func (p *PaginateContext) MustEach(fn func(page utils.JSONResult) bool) {
+	utils.E(p.Each(fn))
+}
+
+// Pages returns a channel of the pages, it will be closed when all the pages are fetched or the c is done.
+// The page requests are also canceled when the c is done, cancel the c if you stop reading the channel
+// before it's closed, or the fetching goroutine will be blocked forever.
+// Use Err to get the error after the channel is closed.
+func (p *PaginateContext) Pages(c context.Context) <-chan utils.JSONResult {
+	ch := make(chan utils.JSONResult)
+
+	// the requests should be canceled by either the c or the context of the request
+	reqCtx, cancel := context.WithCancel(c)
+	req := p.req.clone(p.req.url)
+	if p.req.context != nil {
+		go func() {
+			select {
+			case <-p.req.context.Done():
+				cancel()
+			case <-reqCtx.Done():
+			}
+		}()
+	}
+	req.context = reqCtx
+
+	go func() {
+		defer close(ch)
+		defer cancel()
+
+		err := p.each(req, func(page utils.JSONResult) bool {
+			select {
+			case ch <- page:
+				return true
+			case <-c.Done():
+				return false
+			}
+		})
+		if c.Err() == nil {
+			// the error caused by stopping isn't an error of the pagination
+			p.err = err
+		}
+	}()
+
+	return ch
+}
+
+// Err returns the error of the Pages
+func (p *PaginateContext) Err() error {
+	return p.err
+}
+
+// clone the request settings for a new url
+func (ctx *ReqContext) clone(u string) *ReqContext {
+	c := *ctx
+	c.url = u
+	c.header = ctx.header.Clone()
+	c.request = nil
+	c.response = nil
+	c.resBytes = nil
+	c.timeoutCancel = nil
+	c.attempts = 0
+
+	if ctx.compress != "" {
+		// it will be set again if the body of the clone is compressed
+		c.header.Del("Content-Encoding")
+	}
+
+	// the body has been consumed by the previous request, rewind it if possible
+	if seeker, ok := ctx.body.(io.Seeker); ok {
+		if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+			c.err = err
+		}
+	} else {
+		c.body = nil
+	}
+
+	if ctx.timing != nil {
+		c.timing = &Timing{}
+	}
+	return &c
+}
+
+// nextLink parses the next url from the values of Link headers, such as `<https://a.com?page=2>; rel="next"`.
+// The url and the quoted parameter values may contain commas and semicolons.
+func nextLink(values []string) string {
+	for _, v := range values {
+		for {
+			v = strings.TrimLeft(v, " \t,")
+			if !strings.HasPrefix(v, "<") {
+				break
+			}
+			end := strings.IndexByte(v, '>')
+			if end < 0 {
+				break
+			}
+			target := v[1:end]
+
+			var params []string
+			params, v = linkParams(v[end+1:])
+			for _, param := range params {
+				kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+				if len(kv) != 2 || strings.ToLower(strings.TrimSpace(kv[0])) != "rel" {
+					continue
+				}
+				for _, rel := range strings.Fields(strings.Trim(strings.TrimSpace(kv[1]), `"`)) {
+					if strings.ToLower(rel) == "next" {
+						return target
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// linkParams splits the parameters of a link by the semicolons until the comma that ends the link,
+// the separators in the quoted strings are ignored, it returns the params and the rest links
+func linkParams(s string) (params []string, rest string) {
+	quoted := false
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' && quoted:
+			i++
+		case c == '"':
+			quoted = !quoted
+		case c == ';' && !quoted:
+			params = append(params, s[start:i])
+			start = i + 1
+		case c == ',' && !quoted:
+			return append(params, s[start:i]), s[i+1:]
+		}
+	}
+	return append(params, s[start:]), ""
+}
+
+func countItems(page utils.JSONResult, path string) int {
+	if path == "" {
+		return len(page.Array())
+	}
+	return len(page.Get(path).Array())
+}
+
+func queryInt(u, param string, defaultVal int) (int, error) {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return 0, err
+	}
+
+	v := parsed.Query().Get(param)
+	if v == "" {
+		return defaultVal, nil
+	}
+	return strconv.Atoi(v)
+}
+
+func setQuery(u, param, value string) (string, error) {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return "", err
+	}
+
+	q := parsed.Query()
+	q.Set(param, value)
+	parsed.RawQuery = q.Encode()
+	return parsed.String(), nil
+}
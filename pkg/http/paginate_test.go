@@ -0,0 +1,209 @@
+package http_test
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/ysmood/kit"
+)
+
+func (s *RequestSuite) TestPaginateLink() {
+	path, url := s.path()
+
+	s.router.GET(path, func(c kit.GinContext) {
+		page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+		if page < 3 {
+			c.Header("Link", `<`+path+`?page=1>; rel="first", <`+path+`?page=`+strconv.Itoa(page+1)+`>; rel="next"`)
+		}
+		c.JSON(200, []int{page})
+	})
+
+	list := []int64{}
+	kit.Req(url).Paginate().MustEach(func(page kit.JSONResult) bool {
+		list = append(list, page.Get("0").Int())
+		return true
+	})
+	s.Equal([]int64{1, 2, 3}, list)
+
+	list = []int64{}
+	kit.Req(url).Paginate().MustEach(func(page kit.JSONResult) bool {
+		list = append(list, page.Get("0").Int())
+		return len(list) < 2
+	})
+	s.Equal([]int64{1, 2}, list)
+
+	list = []int64{}
+	kit.Req(url).Paginate().Max(1).MustEach(func(page kit.JSONResult) bool {
+		list = append(list, page.Get("0").Int())
+		return true
+	})
+	s.Equal([]int64{1}, list)
+}
+
+func (s *RequestSuite) TestPaginateTimeout() {
+	path, url := s.path()
+
+	s.router.POST(path, func(c kit.GinContext) {
+		page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+		if page < 3 {
+			c.Header("Link", `<`+path+`?page=`+strconv.Itoa(page+1)+`>; rel="next"`)
+		}
+		c.JSON(200, []interface{}{page, c.PostForm("a")})
+	})
+
+	list := []string{}
+	each := func(page kit.JSONResult) bool {
+		list = append(list, page.Get("0").String()+page.Get("1").String())
+		return true
+	}
+
+	kit.Req(url).Post().Form("a", "x").Timeout(time.Second).Trace(false).Paginate().MustEach(each)
+	s.Equal([]string{"1x", "2x", "3x"}, list)
+
+	list = []string{}
+	kit.Session().Timeout(time.Second).Req(url).Post().Form("a", "y").Paginate().MustEach(each)
+	s.Equal([]string{"1y", "2y", "3y"}, list)
+}
+
+func (s *RequestSuite) TestPaginateCursor() {
+	path, url := s.path()
+
+	s.router.GET(path, func(c kit.GinContext) {
+		s.Equal("ok", c.GetHeader("x"))
+
+		switch c.Query("cursor") {
+		case "":
+			c.JSON(200, kit.JSON(`{"items": [1], "meta": {"next": "a"}}`).Value())
+		case "a":
+			c.JSON(200, kit.JSON(`{"items": [2], "meta": {"next": "b"}}`).Value())
+		default:
+			c.JSON(200, kit.JSON(`{"items": [3], "meta": {}}`).Value())
+		}
+	})
+
+	list := []int64{}
+	kit.Req(url).Header("x", "ok").Paginate().Cursor("meta.next", "cursor").MustEach(func(page kit.JSONResult) bool {
+		list = append(list, page.Get("items.0").Int())
+		return true
+	})
+	s.Equal([]int64{1, 2, 3}, list)
+}
+
+func (s *RequestSuite) TestPaginatePageOffset() {
+	path, url := s.path()
+
+	items := []int{1, 2, 3, 4, 5}
+
+	s.router.GET(path, func(c kit.GinContext) {
+		offset, _ := strconv.Atoi(c.DefaultQuery("offset", "-1"))
+		if offset < 0 {
+			page, _ := strconv.Atoi(c.DefaultQuery("page", "0"))
+			offset = page * 2
+		}
+		end := offset + 2
+		if offset > len(items) {
+			offset = len(items)
+		}
+		if end > len(items) {
+			end = len(items)
+		}
+		c.JSON(200, map[string]interface{}{"data": items[offset:end]})
+	})
+
+	list := []int64{}
+	kit.Req(url).Paginate().Page("page", 0, "data").MustEach(func(page kit.JSONResult) bool {
+		for _, v := range page.Get("data").Array() {
+			list = append(list, v.Int())
+		}
+		return true
+	})
+	s.Equal([]int64{1, 2, 3, 4, 5}, list)
+
+	list = []int64{}
+	kit.Req(url).Query("offset", "1").Paginate().Offset("offset", "data").MustEach(func(page kit.JSONResult) bool {
+		for _, v := range page.Get("data").Array() {
+			list = append(list, v.Int())
+		}
+		return true
+	})
+	s.Equal([]int64{2, 3, 4, 5}, list)
+}
+
+func (s *RequestSuite) TestPaginatePages() {
+	path, url := s.path()
+
+	s.router.GET(path, func(c kit.GinContext) {
+		page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+		c.JSON(200, []int{page})
+	})
+
+	c, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	p := kit.Req(url).Paginate().Page("page", 1, "")
+	pages := p.Pages(c)
+	list := []int64{}
+	for page := range pages {
+		list = append(list, page.Get("0").Int())
+		if len(list) == 3 {
+			break
+		}
+	}
+	cancel()
+	for range pages {
+	}
+	s.Equal([]int64{1, 2, 3}, list)
+	s.Nil(p.Err())
+
+	p = kit.Req("http://127.0.0.1:1").Paginate()
+	for range p.Pages(context.Background()) {
+		s.Fail("should not reach")
+	}
+	s.Error(p.Err())
+}
+
+func (s *RequestSuite) TestPaginatePagesCancel() {
+	path, url := s.path()
+
+	started := make(chan kit.Nil)
+	canceled := make(chan kit.Nil)
+	s.router.GET(path, func(c kit.GinContext) {
+		if c.Query("page") == "" {
+			c.Header("Link", `<`+path+`?ids=1,2&page=2>; title="a, b; c"; rel="next"`)
+			c.JSON(200, []int{1})
+			return
+		}
+		s.Equal("1,2", c.Query("ids"))
+		close(started)
+
+		// the request of the second page should be canceled with the c
+		<-c.Request.Context().Done()
+		close(canceled)
+	})
+
+	c, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	p := kit.Req(url).Paginate()
+	pages := p.Pages(c)
+	s.Equal(int64(1), (<-pages).Get("0").Int())
+	<-started
+	cancel()
+
+	for range pages {
+	}
+	<-canceled
+	s.Nil(p.Err())
+}
+
+func (s *RequestSuite) TestPaginateErr() {
+	path, url := s.path()
+
+	s.router.GET(path, func(c kit.GinContext) {
+		c.JSON(200, []int{1})
+	})
+
+	s.Error(kit.Req(url+"?page=x").Paginate().Page("page", 1, "").Each(func(kit.JSONResult) bool { return true }))
+	s.Error(kit.Req(url+"?offset=x").Paginate().Offset("offset", "").Each(func(kit.JSONResult) bool { return true }))
+}
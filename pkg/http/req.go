@@ -9,7 +9,6 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/cookiejar"
-	"net/url"
 	"sort"
 	"strings"
 	"time"
@@ -36,10 +35,34 @@ type ReqContext struct {
 	stringBody string
 	body       io.Reader
 	resBytes   []byte
-	proxy      string
+
+	startTime time.Time
+	duration  time.Duration
+	timing    *Timing
+	traceLog  bool
+
+	transportOptions []transportOption
+
+	strictDecode bool
+	expectStatus func(int) bool
+
+	compress string
+
+	multipart         []multipartPart
+	multipartBoundary string
 
 	timeout       time.Duration
 	timeoutCancel func()
+
+	// the error that is deferred to the sending of the request
+	err error
+
+	middlewares []Middleware
+	bare        bool // skip the DefaultMiddlewares, for the internal requests
+
+	retrySleeper utils.Sleeper
+	shouldRetry  func(*http.Response, error) bool
+	attempts     int
 }
 
 // Req creates http request instance
@@ -50,6 +73,13 @@ func Req(url string) *ReqContext {
 	}
 }
 
+func newClient() *http.Client {
+	cookie, _ := cookiejar.New(nil)
+	c := *http.DefaultClient // clone
+	c.Jar = cookie
+	return &c
+}
+
 // Context sets the context of the request
 func (ctx *ReqContext) Context(c context.Context) *ReqContext {
 	ctx.context = c
@@ -74,10 +104,9 @@ func (ctx *ReqContext) URL(url string) *ReqContext {
 	return ctx
 }
 
-// Proxy sets the proxy for request
+// Proxy sets the proxy for request, the scheme can be http, https or socks5
 func (ctx *ReqContext) Proxy(url string) *ReqContext {
-	ctx.proxy = url
-	return ctx
+	return ctx.transport(proxyOption(url))
 }
 
 // Post sets the request method to POST
@@ -164,6 +193,10 @@ func (ctx *ReqContext) StringBody(s string) *ReqContext {
 }
 
 func (ctx *ReqContext) getBody() (io.Reader, error) {
+	if ctx.multipart != nil {
+		return ctx.multipartBody(), nil
+	}
+
 	if ctx.stringBody != "" {
 		return strings.NewReader(ctx.stringBody), nil
 	}
@@ -179,23 +212,65 @@ func (ctx *ReqContext) getBody() (io.Reader, error) {
 	return ctx.body, nil
 }
 
-// Do the request
+// Do the request and read the whole response body, so that the connection and the resources held by
+// the middlewares, such as the slot of the Limiter, are released. Use the Response to stream the body.
 func (ctx *ReqContext) Do() error {
+	err := ctx.do()
+	if err != nil {
+		return err
+	}
+
+	if ctx.resBytes == nil {
+		ctx.resBytes, err = readBody(ctx.response.Body)
+		if err != nil {
+			return err
+		}
+	}
+	ctx.response.Body = ioutil.NopCloser(bytes.NewReader(ctx.resBytes))
+
+	return nil
+}
+
+// do sends the request without reading the response body
+func (ctx *ReqContext) do() error {
 	req, err := ctx.Request()
 	if err != nil {
 		return err
 	}
 
-	res, err := ctx.client.Do(req)
+	ctx.startTime = time.Now()
+	res, err := ctx.send(req)
+	ctx.duration = time.Since(ctx.startTime)
+
+	if ctx.timing != nil {
+		ctx.timing.done()
+		if err == nil {
+			ctx.logTrace()
+		}
+	}
+
+	if err != nil {
+		if ctx.timeout != 0 {
+			ctx.timeoutCancel()
+		}
+		return err
+	}
+	err = decompress(res)
 	if err != nil {
+		_ = res.Body.Close()
+		if ctx.timeout != 0 {
+			ctx.timeoutCancel()
+		}
 		return err
 	}
+
 	if ctx.timeout != 0 {
-		ctx.timeoutCancel()
+		// the timeout also covers the reading of the body
+		res.Body = &cancelBody{res.Body, ctx.timeoutCancel}
 	}
 	ctx.response = res
 
-	return nil
+	return ctx.checkStatus()
 }
 
 // MustDo send request, panic if request fails
@@ -209,30 +284,31 @@ func (ctx *ReqContext) Request() (*http.Request, error) {
 		return ctx.request, nil
 	}
 
-	if ctx.context == nil {
-		ctx.context = context.Background()
+	if ctx.err != nil {
+		return nil, ctx.err
+	}
+
+	// keep ctx.context as the one set by the caller, so that it can be reused by the clones of ctx
+	c := ctx.context
+	if c == nil {
+		c = context.Background()
 	}
 
 	if ctx.timeout != 0 {
-		c, cancel := context.WithTimeout(ctx.context, ctx.timeout)
-		ctx.Context(c)
-		ctx.timeoutCancel = cancel
+		c, ctx.timeoutCancel = context.WithTimeout(c, ctx.timeout)
+	}
+
+	if ctx.timing != nil {
+		c = ctx.timing.withTrace(c)
 	}
 
 	if ctx.client == nil {
-		cookie, _ := cookiejar.New(nil)
-		c := *http.DefaultClient // clone
-		ctx.client = &c
-		ctx.client.Jar = cookie
+		ctx.client = newClient()
 	}
 
-	if ctx.proxy != "" {
-		proxyURL, err := url.Parse(ctx.proxy)
-		if err != nil {
-			return nil, err
-		}
-		transport := &http.Transport{Proxy: http.ProxyURL(proxyURL)}
-		ctx.client.Transport = transport
+	err := ctx.applyTransport()
+	if err != nil {
+		return nil, err
 	}
 
 	body, err := ctx.getBody()
@@ -240,11 +316,37 @@ func (ctx *ReqContext) Request() (*http.Request, error) {
 		return nil, err
 	}
 
-	req, err := http.NewRequestWithContext(ctx.context, ctx.method, ctx.url, body)
+	if ctx.compress != "" && ctx.multipart == nil && body != nil {
+		body, err = ctx.compressBody(body)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// the client closes the body after each attempt, keep it open for the retries
+	seeker, rewindable := body.(io.ReadSeeker)
+	if _, ok := body.(io.Closer); ok && rewindable && ctx.retrySleeper != nil {
+		body = ioutil.NopCloser(seeker)
+	}
+
+	req, err := http.NewRequestWithContext(c, ctx.method, ctx.url, body)
 	if err != nil {
 		return nil, err
 	}
 
+	if ctx.multipart != nil {
+		req.GetBody = func() (io.ReadCloser, error) {
+			return ctx.multipartBody(), nil
+		}
+	}
+
+	if rewindable && req.GetBody == nil {
+		req.GetBody = func() (io.ReadCloser, error) {
+			_, err := seeker.Seek(0, io.SeekStart)
+			return ioutil.NopCloser(seeker), err
+		}
+	}
+
 	req.Header = ctx.header
 	req.Host = ctx.host
 
@@ -256,10 +358,14 @@ func (ctx *ReqContext) Request() (*http.Request, error) {
 // Response sends request, get response
 func (ctx *ReqContext) Response() (*http.Response, error) {
 	if ctx.response != nil {
+		err := ctx.checkStatus()
+		if err != nil {
+			return nil, err
+		}
 		return ctx.response, nil
 	}
 
-	err := ctx.Do()
+	err := ctx.do()
 	if err != nil {
 		return nil, err
 	}
@@ -289,6 +395,16 @@ func (ctx *ReqContext) MustBytes() []byte {
 	return utils.E(ctx.Bytes())[0].([]byte)
 }
 
+type cancelBody struct {
+	io.ReadCloser
+	cancel func()
+}
+
+func (b *cancelBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
+
 func readBody(b io.ReadCloser) ([]byte, error) {
 	body, err := ioutil.ReadAll(b)
 	if err != nil {
@@ -341,42 +457,48 @@ func paramsToForm(params []interface{}) map[string]interface{} {
 
 // MustCurl generates request and response details in curl style.
 // Useful when reproduce request on other systems with minimum dependencies.
-// For now gzip is not handled.
 func (ctx *ReqContext) MustCurl() string {
-	// get body
-	body, err := ctx.getBody()
+	cmd, err := ctx.CurlCommand()
 	utils.E(err)
-	if body != nil {
-		bodyData, err := ioutil.ReadAll(body)
-		utils.E(err)
-		ctx.stringBody = string(bodyData)
-	}
-	stringBody := ""
-	if ctx.stringBody != "" {
-		stringBody = " \\\n  -d " + shellescape.Quote(ctx.stringBody)
-	}
 
 	res, err := ctx.Response()
 	utils.E(err)
 
-	req, err := ctx.Request()
-	utils.E(err)
+	return cmd + "\n\n" + formatResponse(res, ctx.MustBytes())
+}
 
+func curlData(body string) string {
+	if body == "" {
+		return ""
+	}
+	return " \\\n  -d " + shellescape.Quote(body)
+}
+
+func formatCurl(req *http.Request, data string) string {
 	// request header
 	reqHeaderStr := ""
 	for _, h := range headerToArr(req.Header) {
 		reqHeaderStr += " \\\n  -H " + shellescape.Quote(h[0]+": "+h[1])
 	}
 
+	return utils.S(
+		"curl -X {{.method}} {{.url}}{{.header}}{{.data}}",
+		"method", shellescape.Quote(req.Method),
+		"url", shellescape.Quote(req.URL.String()),
+		"header", reqHeaderStr,
+		"data", data,
+	)
+}
+
+func formatResponse(res *http.Response, resBytes []byte) string {
 	resStr := res.Proto + " " + res.Status + "\n"
 
 	for _, h := range headerToArr(res.Header) {
 		resStr += h[0] + ": " + h[1] + "\n"
 	}
 
-	resBytes := ctx.MustBytes()
 	var obj interface{}
-	err = json.Unmarshal(resBytes, &obj)
+	err := json.Unmarshal(resBytes, &obj)
 	if err == nil {
 		resBytes, _ = json.MarshalIndent(obj, "", "  ")
 	} else if !utf8.Valid(resBytes) {
@@ -385,14 +507,7 @@ func (ctx *ReqContext) MustCurl() string {
 
 	resStr += "\n" + string(resBytes)
 
-	return utils.S(
-		"curl -X {{.method}} {{.url}}{{.header}}{{.data}}\n\n{{.resStr}}",
-		"method", shellescape.Quote(req.Method),
-		"url", shellescape.Quote(req.URL.String()),
-		"header", reqHeaderStr,
-		"data", stringBody,
-		"resStr", strings.Trim(resStr, "\n"),
-	)
+	return strings.Trim(resStr, "\n")
 }
 
 func headerToArr(header http.Header) [][]string {
@@ -0,0 +1,143 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ysmood/kit/pkg/utils"
+)
+
+// Retry resends the request while shouldRetry returns true, the sleeper decides how long to wait
+// between attempts and when to give up, such as Retry(utils.BackoffSleeper(...), nil).
+// If shouldRetry is nil, RetryOnFailure will be used.
+// The Retry-After response header is honored as the minimum wait.
+// Only requests without a body or with a string, json, form or io.Seeker body can be resent.
+func (ctx *ReqContext) Retry(s utils.Sleeper, shouldRetry func(*http.Response, error) bool) *ReqContext {
+	if shouldRetry == nil {
+		shouldRetry = RetryOnFailure
+	}
+	ctx.retrySleeper = s
+	ctx.shouldRetry = shouldRetry
+	return ctx
+}
+
+// Attempts returns how many times the request has been sent
+func (ctx *ReqContext) Attempts() int {
+	return ctx.attempts
+}
+
+// RetryOnFailure retries on network errors, 429 and 5xx status codes
+func RetryOnFailure(res *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	return res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500
+}
+
+func (ctx *ReqContext) send(req *http.Request) (*http.Response, error) {
+	ctx.attempts = 0
+	rt := ctx.roundTrip()
+
+	for {
+		ctx.attempts++
+
+		if ctx.timing != nil {
+			ctx.timing.reset()
+		}
+
+		res, err := rt(req)
+
+		if ctx.retrySleeper == nil || !ctx.shouldRetry(res, err) || !canRewind(req) {
+			return res, err
+		}
+
+		start := time.Now()
+		wait := retryAfter(res)
+
+		sleepErr := ctx.retrySleeper(req.Context())
+		if sleepErr != nil && req.Context().Err() == nil {
+			// give up, use the last result
+			return res, err
+		}
+
+		if res != nil {
+			_, _ = io.Copy(ioutil.Discard, res.Body)
+			_ = res.Body.Close()
+		}
+
+		if sleepErr != nil {
+			return nil, sleepErr
+		}
+
+		err = sleepCtx(req.Context(), wait-time.Since(start))
+		if err != nil {
+			return nil, err
+		}
+
+		req, err = rewindRequest(req)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func canRewind(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	r := req.Clone(req.Context())
+
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		r.Body = body
+	}
+
+	return r, nil
+}
+
+// retryAfter parses the Retry-After header, it can be seconds or a http date
+func retryAfter(res *http.Response) time.Duration {
+	if res == nil {
+		return 0
+	}
+
+	v := res.Header.Get("Retry-After")
+	if v == "" {
+		return 0
+	}
+
+	if s, err := strconv.Atoi(v); err == nil {
+		return time.Duration(s) * time.Second
+	}
+
+	if t, err := http.ParseTime(v); err == nil {
+		return time.Until(t)
+	}
+
+	return 0
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,29 @@
+package http
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRetryAfter(t *testing.T) {
+	res := &http.Response{Header: http.Header{}}
+	if retryAfter(res) != 0 || retryAfter(nil) != 0 {
+		panic("should be zero")
+	}
+
+	res.Header.Set("Retry-After", "2")
+	if retryAfter(res) != 2*time.Second {
+		panic("wrong seconds")
+	}
+
+	res.Header.Set("Retry-After", time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+	if d := retryAfter(res); d <= 50*time.Second || d > time.Minute {
+		panic("wrong date")
+	}
+
+	res.Header.Set("Retry-After", "x")
+	if retryAfter(res) != 0 {
+		panic("should ignore invalid value")
+	}
+}
@@ -0,0 +1,152 @@
+package http_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ysmood/kit"
+)
+
+func (s *RequestSuite) TestRetry() {
+	path, url := s.path()
+
+	count := 0
+	s.router.POST(path, func(c kit.GinContext) {
+		count++
+		data, _ := c.GetRawData()
+		if count < 3 {
+			c.String(500, "")
+			return
+		}
+		c.String(200, string(data))
+	})
+
+	c := kit.Req(url).Post().StringBody("ok").Retry(kit.CountSleeper(5), nil)
+
+	s.Equal("ok", c.MustString())
+	s.Equal(3, c.Attempts())
+}
+
+func (s *RequestSuite) TestRetryTrace() {
+	path, url := s.path()
+
+	count := 0
+	s.router.GET(path, func(c kit.GinContext) {
+		count++
+		if count < 2 {
+			time.Sleep(100 * time.Millisecond)
+			c.String(500, "")
+			return
+		}
+		c.String(200, "ok")
+	})
+
+	c := kit.Req(url).Retry(kit.CountSleeper(5), nil).Trace(false)
+
+	s.Equal("ok", c.MustString())
+	s.Equal(2, c.Attempts())
+	s.Less(int64(c.Timing().Total), int64(100*time.Millisecond))
+}
+
+func (s *RequestSuite) TestRetryGiveUp() {
+	path, url := s.path()
+
+	s.router.GET(path, func(c kit.GinContext) {
+		c.String(503, "busy")
+	})
+
+	c := kit.Req(url).Retry(kit.CountSleeper(2), nil)
+
+	s.Equal(503, c.MustResponse().StatusCode)
+	s.Equal("busy", c.MustString())
+	s.Equal(3, c.Attempts())
+}
+
+func (s *RequestSuite) TestRetryCustomFilter() {
+	path, url := s.path()
+
+	s.router.GET(path, func(c kit.GinContext) {
+		c.String(404, "")
+	})
+
+	c := kit.Req(url).Retry(kit.CountSleeper(5), func(res *http.Response, err error) bool {
+		return false
+	})
+
+	c.MustDo()
+	s.Equal(1, c.Attempts())
+}
+
+func (s *RequestSuite) TestRetryFileBody() {
+	path, url := s.path()
+
+	count := 0
+	s.router.PUT(path, func(c kit.GinContext) {
+		count++
+		data, _ := c.GetRawData()
+		if count < 2 {
+			c.String(500, "")
+			return
+		}
+		c.String(200, string(data))
+	})
+
+	p := filepath.Join("tmp", kit.RandString(8))
+	kit.E(kit.OutputFile(p, "file", nil))
+	defer func() { _ = kit.Remove("tmp") }()
+
+	f, err := os.Open(p)
+	kit.E(err)
+	defer func() { _ = f.Close() }()
+
+	c := kit.Req(url).Put().Body(f).Retry(kit.CountSleeper(5), nil)
+
+	s.Equal("file", c.MustString())
+	s.Equal(2, c.Attempts())
+}
+
+func (s *RequestSuite) TestRetryAfter() {
+	path, url := s.path()
+
+	count := 0
+	var last time.Time
+	var gap time.Duration
+	s.router.GET(path, func(c kit.GinContext) {
+		count++
+		if count == 1 {
+			last = time.Now()
+			c.Header("Retry-After", "1")
+			c.String(429, "")
+			return
+		}
+		gap = time.Since(last)
+	})
+
+	kit.Req(url).Retry(kit.CountSleeper(5), nil).MustDo()
+
+	s.GreaterOrEqual(int64(gap), int64(time.Second))
+}
+
+func (s *RequestSuite) TestRetryTimeout() {
+	path, url := s.path()
+
+	s.router.GET(path, func(c kit.GinContext) {
+		c.String(500, "")
+	})
+
+	sleeper := kit.BackoffSleeper(time.Hour, time.Hour, nil)
+	err := kit.Req(url).Timeout(100*time.Millisecond).Retry(sleeper, nil).Do()
+
+	s.True(errors.Is(err, context.DeadlineExceeded))
+}
+
+func (s *RequestSuite) TestRetryNetworkErr() {
+	c := kit.Req("http://127.0.0.1:1").Retry(kit.CountSleeper(2), nil)
+
+	s.Error(c.Do())
+	s.Equal(3, c.Attempts())
+}
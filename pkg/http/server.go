@@ -1,10 +1,17 @@
 package http
 
 import (
+	"context"
+	"crypto/tls"
 	"net"
 	"net/http"
+	"os"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	gos "github.com/ysmood/kit/pkg/os"
 	"github.com/ysmood/kit/pkg/utils"
 )
 
@@ -14,6 +21,27 @@ type ServerContext struct {
 	Listener net.Listener
 
 	server *http.Server
+
+	lock        sync.Mutex
+	ready       chan utils.Nil
+	readyOnce   sync.Once
+	closing     chan utils.Nil
+	closingOnce sync.Once
+	stopped     chan utils.Nil
+	shutdownErr error
+	onStart     []func()
+	onShutdown  []func()
+
+	signals      []os.Signal
+	drainTimeout time.Duration
+
+	tlsConfig *tls.Config
+	ca        []byte
+
+	readyChecks []readyCheck
+
+	// the error that is deferred to Do
+	err error
 }
 
 // GinContext ...
@@ -24,7 +52,9 @@ type GinContext = *gin.Context
 // port is ready.
 func Server(address string) (*ServerContext, error) {
 	s := &ServerContext{
-		server: &http.Server{},
+		server:  &http.Server{},
+		ready:   make(chan utils.Nil),
+		closing: make(chan utils.Nil),
 	}
 
 	gin.SetMode(gin.ReleaseMode)
@@ -53,13 +83,138 @@ func (ctx *ServerContext) Set(server *http.Server) *ServerContext {
 	return ctx
 }
 
-// Do start the handler loop
+// OnStart adds a hook that will be called before the server starts to accept connections
+func (ctx *ServerContext) OnStart(fn func()) *ServerContext {
+	ctx.onStart = append(ctx.onStart, fn)
+	return ctx
+}
+
+// OnShutdown adds a hook that will be called after the connections are drained by Shutdown
+func (ctx *ServerContext) OnShutdown(fn func()) *ServerContext {
+	ctx.onShutdown = append(ctx.onShutdown, fn)
+	return ctx
+}
+
+// ShutdownOnSignal makes the server shutdown when it gets any of the signals,
+// the default signals are SIGINT and SIGTERM. The drainTimeout is the max time to
+// wait for the active connections to finish.
+func (ctx *ServerContext) ShutdownOnSignal(drainTimeout time.Duration, signals ...os.Signal) *ServerContext {
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	ctx.signals = signals
+	ctx.drainTimeout = drainTimeout
+	return ctx
+}
+
+// Ready returns a channel that will be closed once the server is accepting connections
+func (ctx *ServerContext) Ready() <-chan utils.Nil {
+	return ctx.ready
+}
+
+// Do start the handler loop, it returns the result of Shutdown if the server is shutdown
 func (ctx *ServerContext) Do() error {
+	if ctx.err != nil {
+		return ctx.err
+	}
+
 	ctx.server.Handler = ctx.Engine
-	return ctx.server.Serve(ctx.Listener)
+	if ctx.tlsConfig != nil {
+		ctx.server.TLSConfig = ctx.tlsConfig
+	}
+
+	// the Do can be called again after the serving stops, but the server only starts once
+	started := false
+	ctx.readyOnce.Do(func() {
+		started = true
+		for _, fn := range ctx.onStart {
+			fn()
+		}
+	})
+
+	if ctx.signals != nil {
+		c, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go ctx.waitSignal(c)
+	}
+
+	if started {
+		close(ctx.ready)
+	}
+
+	var err error
+	if ctx.tlsConfig == nil {
+		err = ctx.server.Serve(ctx.Listener)
+	} else {
+		err = ctx.server.ServeTLS(ctx.Listener, "", "")
+	}
+
+	// such as the Listener is closed directly, stop the background jobs too
+	ctx.close()
+
+	ctx.lock.Lock()
+	stopped := ctx.stopped
+	ctx.lock.Unlock()
+
+	if stopped != nil {
+		<-stopped
+		return ctx.shutdownErr
+	}
+	return err
 }
 
 // MustDo ...
 func (ctx *ServerContext) MustDo() {
 	utils.E(ctx.Do())
 }
+
+// Shutdown stops the server from accepting new connections, then waits for the active connections
+// to finish until the c is done. If the c is done first, the remaining connections will be closed.
+func (ctx *ServerContext) Shutdown(c context.Context) error {
+	ctx.lock.Lock()
+	if ctx.stopped != nil {
+		stopped := ctx.stopped
+		ctx.lock.Unlock()
+		<-stopped
+		return ctx.shutdownErr
+	}
+	ctx.stopped = make(chan utils.Nil)
+	ctx.lock.Unlock()
+
+	ctx.close()
+
+	err := ctx.server.Shutdown(c)
+	if err != nil {
+		_ = ctx.server.Close()
+	}
+	_ = ctx.Listener.Close()
+
+	for _, fn := range ctx.onShutdown {
+		fn()
+	}
+
+	ctx.shutdownErr = err
+	close(ctx.stopped)
+	return err
+}
+
+// close notifies the background jobs, such as the health checks, to stop
+func (ctx *ServerContext) close() {
+	ctx.closingOnce.Do(func() { close(ctx.closing) })
+}
+
+// MustShutdown ...
+func (ctx *ServerContext) MustShutdown(c context.Context) {
+	utils.E(ctx.Shutdown(c))
+}
+
+func (ctx *ServerContext) waitSignal(c context.Context) {
+	if !gos.WaitSignalContext(c, ctx.signals...) {
+		return
+	}
+
+	timeout, cancel := context.WithTimeout(context.Background(), ctx.drainTimeout)
+	defer cancel()
+
+	_ = ctx.Shutdown(timeout)
+}
@@ -0,0 +1,213 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets of the latency histogram in seconds
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+type readyCheck struct {
+	name  string
+	check func(context.Context) error
+}
+
+// Health adds the GET /healthz and /readyz endpoints. The /healthz responds 200 as long as the server is running.
+// The /readyz responds 200 if all the checks added by ReadyCheck pass, else 503 with the failed checks,
+// it also responds 503 once the server begins to shutdown.
+func (ctx *ServerContext) Health() *ServerContext {
+	ctx.Engine.GET("/healthz", func(c GinContext) {
+		c.String(http.StatusOK, "ok")
+	})
+
+	ctx.Engine.GET("/readyz", func(c GinContext) {
+		ctx.lock.Lock()
+		checks := append([]readyCheck{}, ctx.readyChecks...)
+		ctx.lock.Unlock()
+
+		failed := []string{}
+		for _, rc := range checks {
+			if err := rc.check(c.Request.Context()); err != nil {
+				failed = append(failed, rc.name+": "+err.Error())
+			}
+		}
+
+		select {
+		case <-ctx.closing:
+			c.String(http.StatusServiceUnavailable, "shutting down")
+			return
+		default:
+		}
+
+		if len(failed) > 0 {
+			c.String(http.StatusServiceUnavailable, strings.Join(failed, "\n"))
+			return
+		}
+		c.String(http.StatusOK, "ok")
+	})
+
+	return ctx
+}
+
+// ReadyCheck adds a check for the /readyz, the check should return an error if not ready
+func (ctx *ServerContext) ReadyCheck(name string, check func(context.Context) error) *ServerContext {
+	ctx.lock.Lock()
+	defer ctx.lock.Unlock()
+
+	ctx.readyChecks = append(ctx.readyChecks, readyCheck{name, check})
+	return ctx
+}
+
+// Metrics records the count and latency of the requests per route, and adds the GET /metrics endpoint
+// that responds them in the Prometheus text format. The buckets of the latency histogram are in seconds,
+// the default is DefaultBuckets. The requests that don't match any route are recorded as route "*",
+// the non-standard methods are recorded as method "OTHER".
+// Like the other middlewares, call it before adding the routes.
+func (ctx *ServerContext) Metrics(buckets ...float64) *ServerContext {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64{}, buckets...)
+	sort.Float64s(buckets)
+
+	m := &metrics{buckets: buckets, series: map[metricKey]*metricSeries{}}
+
+	ctx.Engine.Use(func(c GinContext) {
+		start := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "*"
+		}
+		m.observe(metricMethod(c.Request.Method), route, c.Writer.Status(), time.Since(start))
+	})
+
+	ctx.Engine.GET("/metrics", func(c GinContext) {
+		c.Data(http.StatusOK, "text/plain; version=0.0.4; charset=utf-8", []byte(m.format()))
+	})
+
+	return ctx
+}
+
+// metricMethods are the methods recorded as is, the others are recorded as "OTHER",
+// so that the clients can't grow the series with the arbitrary methods
+var metricMethods = map[string]bool{
+	http.MethodGet: true, http.MethodHead: true, http.MethodPost: true,
+	http.MethodPut: true, http.MethodPatch: true, http.MethodDelete: true,
+	http.MethodConnect: true, http.MethodOptions: true, http.MethodTrace: true,
+}
+
+func metricMethod(method string) string {
+	if metricMethods[method] {
+		return method
+	}
+	return "OTHER"
+}
+
+type metricKey struct {
+	method string
+	route  string
+}
+
+type metricSeries struct {
+	codes   map[int]uint64
+	buckets []uint64
+	sum     float64
+	count   uint64
+}
+
+type metrics struct {
+	lock    sync.Mutex
+	buckets []float64
+	series  map[metricKey]*metricSeries
+}
+
+func (m *metrics) observe(method, route string, code int, d time.Duration) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	key := metricKey{method, route}
+	s, has := m.series[key]
+	if !has {
+		s = &metricSeries{codes: map[int]uint64{}, buckets: make([]uint64, len(m.buckets))}
+		m.series[key] = s
+	}
+
+	seconds := d.Seconds()
+	s.codes[code]++
+	s.sum += seconds
+	s.count++
+	for i, le := range m.buckets {
+		if seconds <= le {
+			s.buckets[i]++
+		}
+	}
+}
+
+// format the metrics in the Prometheus text exposition format
+func (m *metrics) format() string {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	keys := []metricKey{}
+	for k := range m.series {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].route == keys[j].route {
+			return keys[i].method < keys[j].method
+		}
+		return keys[i].route < keys[j].route
+	})
+
+	out := &strings.Builder{}
+
+	out.WriteString("# HELP http_requests_total The total number of the http requests.\n")
+	out.WriteString("# TYPE http_requests_total counter\n")
+	for _, k := range keys {
+		s := m.series[k]
+
+		codes := []int{}
+		for code := range s.codes {
+			codes = append(codes, code)
+		}
+		sort.Ints(codes)
+
+		for _, code := range codes {
+			fmt.Fprintf(out, "http_requests_total{method=%s,route=%s,code=\"%d\"} %d\n",
+				labelValue(k.method), labelValue(k.route), code, s.codes[code])
+		}
+	}
+
+	out.WriteString("# HELP http_request_duration_seconds The latency of the http requests.\n")
+	out.WriteString("# TYPE http_request_duration_seconds histogram\n")
+	for _, k := range keys {
+		s := m.series[k]
+		labels := fmt.Sprintf("method=%s,route=%s", labelValue(k.method), labelValue(k.route))
+
+		for i, le := range m.buckets {
+			fmt.Fprintf(out, "http_request_duration_seconds_bucket{%s,le=\"%s\"} %d\n",
+				labels, strconv.FormatFloat(le, 'g', -1, 64), s.buckets[i])
+		}
+		fmt.Fprintf(out, "http_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, s.count)
+		fmt.Fprintf(out, "http_request_duration_seconds_sum{%s} %s\n", labels, strconv.FormatFloat(s.sum, 'g', -1, 64))
+		fmt.Fprintf(out, "http_request_duration_seconds_count{%s} %d\n", labels, s.count)
+	}
+
+	return out.String()
+}
+
+// labelValue quotes and escapes the label value of the Prometheus text format
+func labelValue(v string) string {
+	v = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+	return `"` + v + `"`
+}
@@ -0,0 +1,91 @@
+package http_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ysmood/kit"
+)
+
+func (s *RequestSuite) TestServerHealth() {
+	server, url := s.server()
+
+	var dbErr error
+	server.Health().ReadyCheck("db", func(context.Context) error { return dbErr })
+
+	s.Equal("ok", kit.Req(url+"/healthz").MustString())
+	s.Equal("ok", kit.Req(url+"/readyz").MustString())
+
+	dbErr = errors.New("timeout")
+	res := kit.Req(url + "/readyz").MustResponse()
+	s.Equal(503, res.StatusCode)
+	s.Equal("db: timeout", kit.Req(url+"/readyz").MustString())
+
+	dbErr = nil
+	checking, release := make(chan kit.Nil), make(chan kit.Nil)
+	server.ReadyCheck("slow", func(context.Context) error {
+		close(checking)
+		<-release
+		return nil
+	})
+
+	res = nil
+	done := make(chan kit.Nil)
+	req := kit.Req(url + "/readyz")
+	go func() {
+		res = req.MustResponse()
+		close(done)
+	}()
+	<-checking
+
+	go func() { _ = server.Shutdown(context.Background()) }()
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	<-done
+
+	s.Equal(503, res.StatusCode)
+	s.Equal("shutting down", req.MustString())
+}
+
+func (s *RequestSuite) TestServerMetrics() {
+	server, url := s.server()
+	server.Metrics(0.05, 0.01)
+	server.Engine.GET("/users/:id", func(c kit.GinContext) {
+		if c.Param("id") == "0" {
+			c.Status(404)
+			return
+		}
+		c.String(200, "ok")
+	})
+	server.Engine.POST("/slow", func(c kit.GinContext) {
+		time.Sleep(20 * time.Millisecond)
+	})
+
+	kit.Req(url + "/users/1").MustDo()
+	kit.Req(url + "/users/2").MustDo()
+	kit.Req(url + "/users/0").MustDo()
+	kit.Req(url + "/slow").Post().MustDo()
+	kit.Req(url + "/not-exists").MustDo()
+	kit.Req(url + "/not-exists").Method("FOO").MustDo()
+	kit.Req(url + "/not-exists").Method("BAR").MustDo()
+
+	out := kit.Req(url + "/metrics").MustString()
+
+	s.Contains(out, "# TYPE http_requests_total counter\n"+
+		`http_requests_total{method="GET",route="*",code="404"} 1`+"\n"+
+		`http_requests_total{method="OTHER",route="*",code="404"} 2`+"\n"+
+		`http_requests_total{method="POST",route="/slow",code="200"} 1`+"\n"+
+		`http_requests_total{method="GET",route="/users/:id",code="200"} 2`+"\n"+
+		`http_requests_total{method="GET",route="/users/:id",code="404"} 1`+"\n")
+
+	s.Contains(out, "# TYPE http_request_duration_seconds histogram\n")
+	s.Contains(out, `http_request_duration_seconds_bucket{method="POST",route="/slow",le="0.01"} 0`+"\n"+
+		`http_request_duration_seconds_bucket{method="POST",route="/slow",le="0.05"} 1`+"\n"+
+		`http_request_duration_seconds_bucket{method="POST",route="/slow",le="+Inf"} 1`+"\n")
+	s.Regexp(`http_request_duration_seconds_sum\{method="POST",route="/slow"\} 0\.0[2-4]\d*\n`, out)
+	s.Contains(out, `http_request_duration_seconds_count{method="GET",route="/users/:id"} 3`+"\n")
+
+	out = kit.Req(url + "/metrics").MustString()
+	s.Contains(out, `http_requests_total{method="GET",route="/metrics",code="200"} 1`+"\n")
+}
@@ -0,0 +1,225 @@
+package http
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ysmood/kit/pkg/utils"
+)
+
+// RequestIDKey is the key of the request id in the GinContext, such as c.GetString(kit.RequestIDKey)
+const RequestIDKey = "RequestID"
+
+// Standard uses the RequestID, AccessLog, Recovery and Gzip middlewares.
+// Like all the other middlewares, call it before adding the routes.
+func (ctx *ServerContext) Standard() *ServerContext {
+	return ctx.RequestID().AccessLog().Recovery().Gzip()
+}
+
+// AccessLog logs each request via utils.Log with the colored status code
+func (ctx *ServerContext) AccessLog() *ServerContext {
+	ctx.Engine.Use(func(c GinContext) {
+		start := time.Now()
+
+		c.Next()
+
+		args := []interface{}{
+			utils.C("[server]", "cyan"),
+			utils.C(c.Writer.Status(), statusColor(c.Writer.Status())),
+			c.Request.Method,
+			c.Request.URL.RequestURI(),
+			time.Since(start),
+			c.ClientIP(),
+		}
+		if id := c.GetString(RequestIDKey); id != "" {
+			args = append(args, id)
+		}
+		utils.Log(args...)
+	})
+	return ctx
+}
+
+// Recovery recovers the panics of the handlers, prints them via utils.Err and responds 500
+func (ctx *ServerContext) Recovery() *ServerContext {
+	ctx.Engine.Use(func(c GinContext) {
+		defer func() {
+			r := recover()
+			if r == nil {
+				return
+			}
+			if r == http.ErrAbortHandler {
+				panic(r)
+			}
+
+			utils.Err(utils.C("[server]", "cyan"), c.Request.Method, c.Request.URL.RequestURI(), utils.C(r, "red"))
+			c.AbortWithStatus(http.StatusInternalServerError)
+		}()
+
+		c.Next()
+	})
+	return ctx
+}
+
+// CORS allows the cross origin requests from the origins, use "*" to allow all origins.
+// It also responds the preflight requests. The credentials are not allowed, use CORSWithCredentials for them.
+func (ctx *ServerContext) CORS(origins ...string) *ServerContext {
+	return ctx.cors(false, origins)
+}
+
+// CORSWithCredentials is the same as CORS, but it also allows the credentials, such as cookies,
+// for the origins that are explicitly listed. An origin that is only allowed by "*" won't get the credentials.
+func (ctx *ServerContext) CORSWithCredentials(origins ...string) *ServerContext {
+	return ctx.cors(true, origins)
+}
+
+func (ctx *ServerContext) cors(credentials bool, origins []string) *ServerContext {
+	allowed := map[string]bool{}
+	for _, o := range origins {
+		allowed[o] = true
+	}
+
+	ctx.Engine.Use(func(c GinContext) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		h := c.Writer.Header()
+
+		switch {
+		case allowed[origin]:
+			h.Add("Vary", "Origin")
+			h.Set("Access-Control-Allow-Origin", origin)
+			if credentials {
+				h.Set("Access-Control-Allow-Credentials", "true")
+			}
+		case allowed["*"]:
+			h.Set("Access-Control-Allow-Origin", "*")
+		default:
+			h.Add("Vary", "Origin")
+			c.Next()
+			return
+		}
+
+		if c.Request.Method != http.MethodOptions || c.GetHeader("Access-Control-Request-Method") == "" {
+			c.Next()
+			return
+		}
+
+		h.Set("Access-Control-Allow-Methods", c.GetHeader("Access-Control-Request-Method"))
+		if headers := c.GetHeader("Access-Control-Request-Headers"); headers != "" {
+			h.Set("Access-Control-Allow-Headers", headers)
+		}
+		h.Set("Access-Control-Max-Age", strconv.Itoa(int((10 * time.Minute).Seconds())))
+		c.AbortWithStatus(http.StatusNoContent)
+	})
+	return ctx
+}
+
+// RequestID uses the X-Request-Id header of the request or generates a random one,
+// sets it to the response header and the RequestIDKey of the GinContext
+func (ctx *ServerContext) RequestID() *ServerContext {
+	ctx.Engine.Use(func(c GinContext) {
+		id := c.GetHeader("X-Request-Id")
+		if id == "" {
+			id = utils.RandString(8)
+		}
+
+		c.Set(RequestIDKey, id)
+		c.Header("X-Request-Id", id)
+		c.Next()
+	})
+	return ctx
+}
+
+// Gzip compresses the response body if the client accepts gzip
+func (ctx *ServerContext) Gzip() *ServerContext {
+	ctx.Engine.Use(func(c GinContext) {
+		if !strings.Contains(c.GetHeader("Accept-Encoding"), "gzip") || c.GetHeader("Upgrade") != "" {
+			c.Next()
+			return
+		}
+
+		c.Writer.Header().Add("Vary", "Accept-Encoding")
+
+		w := &gzipWriter{ResponseWriter: c.Writer, gz: gzip.NewWriter(c.Writer)}
+		c.Writer = w
+
+		// restore the writer even if the handler panics, so that the Recovery won't respond via the gzipWriter
+		defer func() {
+			c.Writer = w.ResponseWriter
+			if w.compressing {
+				_ = w.gz.Close()
+			}
+		}()
+
+		c.Next()
+	})
+	return ctx
+}
+
+// gzipWriter only sets the Content-Encoding header when the body is written,
+// so that the response without body, such as the one of a panic, won't claim to be compressed
+type gzipWriter struct {
+	gin.ResponseWriter
+	gz          *gzip.Writer
+	written     bool
+	compressing bool
+}
+
+func (w *gzipWriter) Write(b []byte) (int, error) {
+	if !w.written {
+		w.written = true
+
+		h := w.Header()
+
+		// the header has been sent by the handler, it's too late to compress the body.
+		// Don't compress the body that the handler has encoded, or the partial content of a range request.
+		if w.ResponseWriter.Written() || h.Get("Content-Encoding") != "" ||
+			w.Status() == http.StatusPartialContent {
+			return w.ResponseWriter.Write(b)
+		}
+
+		if _, has := h["Content-Type"]; !has {
+			// sniff the uncompressed data, or the net/http will sniff the compressed one
+			h.Set("Content-Type", http.DetectContentType(b))
+		}
+		h.Set("Content-Encoding", "gzip")
+		h.Del("Content-Length")
+		w.compressing = true
+	}
+
+	if !w.compressing {
+		return w.ResponseWriter.Write(b)
+	}
+	return w.gz.Write(b)
+}
+
+func (w *gzipWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *gzipWriter) Flush() {
+	if w.compressing {
+		_ = w.gz.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+func statusColor(code int) string {
+	switch {
+	case code >= 500:
+		return "red"
+	case code >= 400:
+		return "yellow"
+	case code >= 300:
+		return "cyan"
+	default:
+		return "green"
+	}
+}
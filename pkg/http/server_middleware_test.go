@@ -0,0 +1,158 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"time"
+
+	"github.com/ysmood/kit"
+	"github.com/ysmood/kit/pkg/utils"
+)
+
+func (s *RequestSuite) server() (server *kit.ServerContext, url string) {
+	server = kit.MustServer("127.0.0.1:0")
+	go func() {
+		// the Do returns the timeout error of the Cleanup below
+		if err := server.Do(); !errors.Is(err, context.DeadlineExceeded) {
+			kit.E(err)
+		}
+	}()
+	<-server.Ready()
+
+	// the long-lived connections, such as the websockets, will be closed after the timeout
+	s.T().Cleanup(func() {
+		c, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_ = server.Shutdown(c)
+	})
+
+	return server, "http://" + server.Listener.Addr().String()
+}
+
+func (s *RequestSuite) TestServerStandard() {
+	stdout, stderr := utils.Stdout, utils.Stderr
+	defer func() { utils.Stdout, utils.Stderr = stdout, stderr }()
+
+	out := bytes.NewBuffer(nil)
+	utils.Stdout, utils.Stderr = out, out
+
+	server, url := s.server()
+	server.Standard()
+	server.Engine.GET("/", func(c kit.GinContext) {
+		c.String(200, c.GetString(kit.RequestIDKey))
+	})
+	server.Engine.GET("/panic", func(c kit.GinContext) {
+		panic("err")
+	})
+
+	req := kit.Req(url).Header("X-Request-Id", "id")
+	s.Equal("id", req.MustString())
+	s.Equal("id", req.MustResponse().Header.Get("X-Request-Id"))
+	s.Regexp(`\[server\].+200.+ GET / .+ 127.0.0.1 id\n`, out.String())
+
+	out.Reset()
+	s.Len(kit.Req(url).MustString(), 16)
+
+	out.Reset()
+	res := kit.Req(url+"/panic").Header("Accept-Encoding", "gzip").MustResponse()
+	s.Equal(500, res.StatusCode)
+	s.False(res.Uncompressed)
+	s.Regexp(`(?s)\[server\].* GET /panic .+err.+goroutine.+500.+ GET /panic `, out.String())
+}
+
+func (s *RequestSuite) TestServerGzip() {
+	server, url := s.server()
+	server.Gzip()
+	server.Engine.GET("/", func(c kit.GinContext) {
+		c.String(200, "ok")
+	})
+	server.Engine.GET("/empty", func(c kit.GinContext) {
+		c.Status(204)
+	})
+	server.Engine.GET("/raw", func(c kit.GinContext) {
+		_, _ = c.Writer.WriteString("<html></html>")
+	})
+	server.Engine.GET("/encoded", func(c kit.GinContext) {
+		c.Header("Content-Encoding", "custom")
+		c.String(200, "encoded")
+	})
+	server.Engine.GET("/range", func(c kit.GinContext) {
+		c.Header("Content-Range", "bytes 0-1/10")
+		c.Data(206, "text/plain", []byte("ab"))
+	})
+
+	req := kit.Req(url).Header("Accept-Encoding", "gzip")
+	s.Equal("ok", req.MustString())
+	s.True(req.MustResponse().Uncompressed)
+
+	req = kit.Req(url).Header("Accept-Encoding", "identity")
+	s.Equal("ok", req.MustString())
+	s.False(req.MustResponse().Uncompressed)
+
+	res := kit.Req(url+"/empty").Header("Accept-Encoding", "gzip").MustResponse()
+	s.Equal(204, res.StatusCode)
+	s.Empty(res.Header.Get("Content-Encoding"))
+
+	req = kit.Req(url+"/raw").Header("Accept-Encoding", "gzip")
+	s.Equal("<html></html>", req.MustString())
+	s.True(req.MustResponse().Uncompressed)
+	s.Equal("text/html; charset=utf-8", req.MustResponse().Header.Get("Content-Type"))
+
+	res = kit.Req(url+"/encoded").Header("Accept-Encoding", "gzip").MustResponse()
+	s.Equal("custom", res.Header.Get("Content-Encoding"))
+	data, _ := ioutil.ReadAll(res.Body)
+	s.Equal("encoded", string(data))
+
+	req = kit.Req(url+"/range").Header("Accept-Encoding", "gzip")
+	s.Equal("ab", req.MustString())
+	s.False(req.MustResponse().Uncompressed)
+	s.Equal(206, req.MustResponse().StatusCode)
+}
+
+func (s *RequestSuite) TestServerCORS() {
+	server, url := s.server()
+	server.CORS("http://a.com")
+	server.Engine.GET("/", func(c kit.GinContext) {
+		c.String(200, "ok")
+	})
+
+	res := kit.Req(url).Header("Origin", "http://a.com").MustResponse()
+	s.Equal("http://a.com", res.Header.Get("Access-Control-Allow-Origin"))
+	s.Equal("Origin", res.Header.Get("Vary"))
+
+	res = kit.Req(url).Header("Origin", "http://b.com").MustResponse()
+	s.Equal(200, res.StatusCode)
+	s.Empty(res.Header.Get("Access-Control-Allow-Origin"))
+
+	res = kit.Req(url).Header("Origin", "http://a.com").Method("OPTIONS").Header(
+		"Access-Control-Request-Method", "PUT",
+		"Access-Control-Request-Headers", "X-Test",
+	).MustResponse()
+	s.Equal(204, res.StatusCode)
+	s.Equal("PUT", res.Header.Get("Access-Control-Allow-Methods"))
+	s.Equal("X-Test", res.Header.Get("Access-Control-Allow-Headers"))
+
+	s.Empty(res.Header.Get("Access-Control-Allow-Credentials"))
+
+	server, url = s.server()
+	server.CORS("*")
+	res = kit.Req(url).Header("Origin", "http://b.com").MustResponse()
+	s.Equal("*", res.Header.Get("Access-Control-Allow-Origin"))
+	s.Empty(res.Header.Get("Access-Control-Allow-Credentials"))
+	s.Equal(404, res.StatusCode)
+}
+
+func (s *RequestSuite) TestServerCORSWithCredentials() {
+	server, url := s.server()
+	server.CORSWithCredentials("http://a.com", "*")
+
+	res := kit.Req(url).Header("Origin", "http://a.com").MustResponse()
+	s.Equal("http://a.com", res.Header.Get("Access-Control-Allow-Origin"))
+	s.Equal("true", res.Header.Get("Access-Control-Allow-Credentials"))
+
+	res = kit.Req(url).Header("Origin", "http://b.com").MustResponse()
+	s.Equal("*", res.Header.Get("Access-Control-Allow-Origin"))
+	s.Empty(res.Header.Get("Access-Control-Allow-Credentials"))
+}
@@ -0,0 +1,280 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/ysmood/kit/pkg/utils"
+)
+
+// ErrNoUpstream is responded when there's no healthy upstream for the reverse proxy
+var ErrNoUpstream = errors.New("no healthy upstream")
+
+// ProxyOptions ...
+type ProxyOptions struct {
+	// StripPrefix removes the prefix from the path before forwarding
+	StripPrefix bool
+
+	// KeepHost forwards the Host header of the client, by default it's the host of the upstream
+	KeepHost bool
+
+	// RequestHeader sets the headers of the upstream requests, an empty value removes the header
+	RequestHeader map[string]string
+
+	// ResponseHeader sets the headers of the responses, an empty value removes the header
+	ResponseHeader map[string]string
+
+	// HealthCheck is the path to check the health of the upstreams, the upstream is healthy
+	// if the status code is 2xx or 3xx. Empty to disable it.
+	HealthCheck string
+
+	// HealthInterval is the interval of the health checks, the default is 10s
+	HealthInterval time.Duration
+
+	// Transport uses the transport options of the request to connect the upstreams,
+	// such as kit.Req("").Insecure().Proxy("socks5://127.0.0.1:1080")
+	Transport *ReqContext
+
+	// Log logs each request and the upstream errors via utils.Log
+	Log bool
+}
+
+type upstream struct {
+	url     *url.URL
+	proxy   *httputil.ReverseProxy
+	healthy int32
+}
+
+type reverseProxy struct {
+	prefix    string
+	opts      *ProxyOptions
+	upstreams []*upstream
+	client    *http.Client
+	count     uint32
+}
+
+// ReverseProxy forwards the requests under the prefix to the upstreams in round-robin,
+// the WebSocket upgrades will be passed through. If the prefix is "/", the requests that
+// don't match any route will be forwarded.
+func (ctx *ServerContext) ReverseProxy(prefix string, opts *ProxyOptions, upstreams ...string) *ServerContext {
+	if opts == nil {
+		opts = &ProxyOptions{}
+	}
+	if opts.Transport == nil {
+		opts.Transport = Req("")
+	}
+	if opts.HealthInterval == 0 {
+		opts.HealthInterval = 10 * time.Second
+	}
+
+	client, err := opts.Transport.upstreamClient()
+	if err != nil {
+		ctx.err = err
+		return ctx
+	}
+
+	p := &reverseProxy{
+		prefix: "/" + strings.Trim(prefix, "/"),
+		opts:   opts,
+		client: client,
+	}
+
+	for _, u := range upstreams {
+		target, err := url.Parse(u)
+		if err != nil {
+			ctx.err = err
+			return ctx
+		}
+
+		up := &upstream{url: target, healthy: 1}
+		up.proxy = &httputil.ReverseProxy{
+			Director:       p.director(target),
+			Transport:      client.Transport,
+			ModifyResponse: p.modifyResponse,
+			ErrorHandler:   p.errorHandler,
+		}
+		p.upstreams = append(p.upstreams, up)
+	}
+
+	if opts.HealthCheck != "" {
+		go p.healthCheck(ctx.closing)
+	}
+
+	if p.prefix == "/" {
+		ctx.Engine.NoRoute(p.serve)
+	} else {
+		ctx.Engine.Any(p.prefix, p.serve)
+		ctx.Engine.Any(p.prefix+"/*path", p.serve)
+	}
+
+	return ctx
+}
+
+// upstreamClient returns the client with the transport options applied
+func (ctx *ReqContext) upstreamClient() (*http.Client, error) {
+	if ctx.err != nil {
+		return nil, ctx.err
+	}
+
+	client := ctx.client
+	if client == nil {
+		client = newClient()
+	}
+
+	client, err := withTransport(client, ctx.transportOptions)
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+func (p *reverseProxy) serve(c GinContext) {
+	start := time.Now()
+
+	up := p.next()
+	if up == nil {
+		c.String(http.StatusBadGateway, ErrNoUpstream.Error())
+	} else {
+		up.proxy.ServeHTTP(c.Writer, c.Request)
+	}
+
+	if p.opts.Log {
+		target := ""
+		if up != nil {
+			target = up.url.String()
+		}
+		utils.Log(
+			utils.C("[proxy]", "cyan"),
+			utils.C(c.Writer.Status(), statusColor(c.Writer.Status())),
+			c.Request.Method,
+			c.Request.URL.RequestURI(),
+			"->",
+			target,
+			time.Since(start),
+		)
+	}
+}
+
+// next returns the next healthy upstream in round-robin
+func (p *reverseProxy) next() *upstream {
+	n := len(p.upstreams)
+	start := int(atomic.AddUint32(&p.count, 1))
+
+	for i := 0; i < n; i++ {
+		up := p.upstreams[(start+i)%n]
+		if atomic.LoadInt32(&up.healthy) == 1 {
+			return up
+		}
+	}
+	return nil
+}
+
+func (p *reverseProxy) director(target *url.URL) func(*http.Request) {
+	return func(req *http.Request) {
+		path := req.URL.Path
+		if p.opts.StripPrefix && p.prefix != "/" {
+			path = "/" + strings.TrimPrefix(strings.TrimPrefix(path, p.prefix), "/")
+		}
+
+		req.Header.Set("X-Forwarded-Host", req.Host)
+		if req.TLS == nil {
+			req.Header.Set("X-Forwarded-Proto", "http")
+		} else {
+			req.Header.Set("X-Forwarded-Proto", "https")
+		}
+
+		req.URL.Scheme = target.Scheme
+		req.URL.Host = target.Host
+		req.URL.Path = strings.TrimSuffix(target.Path, "/") + path
+		req.URL.RawPath = ""
+		if target.RawQuery != "" && req.URL.RawQuery != "" {
+			req.URL.RawQuery = target.RawQuery + "&" + req.URL.RawQuery
+		} else {
+			req.URL.RawQuery = target.RawQuery + req.URL.RawQuery
+		}
+
+		if !p.opts.KeepHost {
+			req.Host = target.Host
+		}
+
+		setHeaders(req.Header, p.opts.RequestHeader)
+	}
+}
+
+func (p *reverseProxy) modifyResponse(res *http.Response) error {
+	setHeaders(res.Header, p.opts.ResponseHeader)
+	return nil
+}
+
+func (p *reverseProxy) errorHandler(w http.ResponseWriter, req *http.Request, err error) {
+	if p.opts.Log && !errors.Is(err, context.Canceled) {
+		utils.Log(utils.C("[proxy]", "cyan"), req.Method, req.URL.String(), utils.C(err, "red"))
+	}
+	w.WriteHeader(http.StatusBadGateway)
+}
+
+func (p *reverseProxy) healthCheck(closing <-chan utils.Nil) {
+	// the pending checks will be canceled once the server is closed
+	c, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for {
+		for _, up := range p.upstreams {
+			go p.check(c, up)
+		}
+
+		select {
+		case <-time.After(p.opts.HealthInterval):
+		case <-closing:
+			return
+		}
+	}
+}
+
+// check sends the plain request, the DefaultMiddlewares are for the user's requests
+func (p *reverseProxy) check(c context.Context, up *upstream) {
+	c, cancel := context.WithTimeout(c, p.opts.HealthInterval)
+	defer cancel()
+
+	u, err := up.url.Parse(p.opts.HealthCheck)
+	if err != nil {
+		atomic.StoreInt32(&up.healthy, 0)
+		return
+	}
+
+	req, err := http.NewRequestWithContext(c, http.MethodGet, u.String(), nil)
+	if err != nil {
+		atomic.StoreInt32(&up.healthy, 0)
+		return
+	}
+
+	res, err := p.client.Do(req)
+	if err == nil {
+		_ = res.Body.Close()
+	}
+	if errors.Is(c.Err(), context.Canceled) {
+		return // the server is closing
+	}
+
+	if err == nil && res.StatusCode >= 200 && res.StatusCode < 400 {
+		atomic.StoreInt32(&up.healthy, 1)
+	} else {
+		atomic.StoreInt32(&up.healthy, 0)
+	}
+}
+
+func setHeaders(h http.Header, headers map[string]string) {
+	for k, v := range headers {
+		if v == "" {
+			h.Del(k)
+		} else {
+			h.Set(k, v)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package http_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/ysmood/kit"
+	"github.com/ysmood/kit/pkg/utils"
+)
+
+func (s *RequestSuite) upstream(name string) (*kit.ServerContext, string) {
+	server, url := s.server()
+	server.Engine.Any("/*path", func(c kit.GinContext) {
+		c.Header("X-Remove", "x")
+		c.String(200, "%s %s %s %s %s %s", name, c.Request.Method, c.Request.URL.RequestURI(),
+			c.Request.Host, c.GetHeader("X-Test"), c.GetHeader("X-Forwarded-Proto"))
+	})
+	return server, url
+}
+
+func (s *RequestSuite) TestServerReverseProxy() {
+	_, a := s.upstream("a")
+	_, b := s.upstream("b")
+
+	server, url := s.server()
+	server.ReverseProxy("/api", &kit.ProxyOptions{
+		StripPrefix:    true,
+		RequestHeader:  map[string]string{"X-Test": "ok"},
+		ResponseHeader: map[string]string{"X-Remove": "", "X-Proxy": "kit"},
+	}, a+"/base", b+"/base")
+	server.Engine.GET("/", func(c kit.GinContext) {
+		c.String(200, "root")
+	})
+
+	s.Equal("root", kit.Req(url).MustString())
+
+	results := []string{}
+	for i := 0; i < 2; i++ {
+		results = append(results, kit.Req(url+"/api/users").Query("id", "1").MustString())
+	}
+	s.ElementsMatch([]string{
+		"a GET /base/users?id=1 " + a[7:] + " ok http",
+		"b GET /base/users?id=1 " + b[7:] + " ok http",
+	}, results)
+
+	res := kit.Req(url + "/api").Post().MustResponse()
+	s.Empty(res.Header.Get("X-Remove"))
+	s.Equal("kit", res.Header.Get("X-Proxy"))
+	s.Regexp(`^\w POST /base/ `, kit.Req(url+"/api").Post().MustString())
+}
+
+func (s *RequestSuite) TestServerReverseProxyRoot() {
+	_, a := s.upstream("a")
+
+	server, url := s.server()
+	server.ReverseProxy("/", &kit.ProxyOptions{KeepHost: true}, a)
+	server.Engine.GET("/local", func(c kit.GinContext) {
+		c.String(200, "local")
+	})
+
+	s.Equal("local", kit.Req(url+"/local").MustString())
+	s.Equal("a PUT /x "+url[7:]+"  http", kit.Req(url+"/x").Put().MustString())
+}
+
+func (s *RequestSuite) TestServerReverseProxyHealthCheck() {
+	stdout := utils.Stdout
+	defer func() { utils.Stdout = stdout }()
+	out := bytes.NewBuffer(nil)
+	utils.Stdout = out
+
+	upA, a := s.upstream("a")
+	_, b := s.upstream("b")
+
+	server, url := s.server()
+	server.ReverseProxy("/", &kit.ProxyOptions{
+		HealthCheck:    "/healthz",
+		HealthInterval: 20 * time.Millisecond,
+		Log:            true,
+	}, a, b)
+	// stop the health checks before the stdout is restored
+	defer server.MustShutdown(context.Background())
+
+	upA.MustShutdown(context.Background())
+	time.Sleep(100 * time.Millisecond)
+
+	for i := 0; i < 3; i++ {
+		s.Regexp(`^b GET /`, kit.Req(url).MustString())
+	}
+	s.Regexp(`\[proxy\].+200.+ GET / -> `+b, out.String())
+
+	server, url = s.server()
+	server.ReverseProxy("/", &kit.ProxyOptions{
+		HealthCheck:    "/healthz",
+		HealthInterval: 20 * time.Millisecond,
+	}, a)
+	defer server.MustShutdown(context.Background())
+	time.Sleep(100 * time.Millisecond)
+
+	res := kit.Req(url).MustResponse()
+	s.Equal(http.StatusBadGateway, res.StatusCode)
+	s.Equal(kit.ErrNoUpstream.Error(), kit.Req(url).MustString())
+}
+
+func (s *RequestSuite) TestServerReverseProxyErr() {
+	server, url := s.server()
+	server.ReverseProxy("/", nil, "http://127.0.0.1:1")
+	s.Equal(http.StatusBadGateway, kit.Req(url).MustResponse().StatusCode)
+
+	s.Error(kit.MustServer(":0").ReverseProxy("/", nil, "://").Do())
+	s.Error(kit.MustServer(":0").ReverseProxy("/", &kit.ProxyOptions{
+		Transport: kit.Req("").CA([]byte("x")),
+	}).Do())
+}
+
+func (s *RequestSuite) TestServerReverseProxyTransport() {
+	srv := tlsServer(false)
+	defer srv.Close()
+
+	server, url := s.server()
+	server.ReverseProxy("/", &kit.ProxyOptions{Transport: kit.Req("").CA(serverCA(srv))}, srv.URL)
+	s.Equal("ok", kit.Req(url).MustString())
+}
+
+func (s *RequestSuite) TestServerReverseProxyWebSocket() {
+	up, a := s.server()
+	up.Engine.GET("/ws", func(c kit.GinContext) {
+		conn, rw, err := c.Writer.Hijack()
+		kit.E(err)
+		defer func() { _ = conn.Close() }()
+
+		_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: test\r\n\r\n")
+		_ = rw.Flush()
+
+		line, _ := rw.ReadString('\n')
+		_, _ = rw.WriteString("echo " + line)
+		_ = rw.Flush()
+	})
+
+	server, url := s.server()
+	server.Standard().ReverseProxy("/", nil, a)
+
+	conn, err := net.Dial("tcp", url[7:])
+	s.Nil(err)
+	defer func() { _ = conn.Close() }()
+
+	_, err = conn.Write([]byte("GET /ws HTTP/1.1\r\nHost: test\r\nAccept-Encoding: gzip\r\nConnection: Upgrade\r\nUpgrade: test\r\n\r\n"))
+	s.Nil(err)
+
+	r := bufio.NewReader(conn)
+	res, err := http.ReadResponse(r, nil)
+	s.Nil(err)
+	s.Equal(101, res.StatusCode)
+
+	_, err = conn.Write([]byte("hi\n"))
+	s.Nil(err)
+
+	line, err := r.ReadString('\n')
+	s.Nil(err)
+	s.Equal("echo hi\n", line)
+}
@@ -0,0 +1,260 @@
+package http
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/radovskyb/watcher"
+	gos "github.com/ysmood/kit/pkg/os"
+	"github.com/ysmood/kit/pkg/utils"
+)
+
+// StaticOptions ...
+type StaticOptions struct {
+	// Listing lists the files of the dirs that have no index.html
+	Listing bool
+
+	// SPA responds the root index.html for the paths that don't exist
+	SPA bool
+
+	// LiveReload watches the files matched by the matcher, when any of them changes it pushes
+	// a reload event to the browsers. Add <script src="{prefix}/_live_reload.js"></script> to the html to use it.
+	LiveReload *gos.Matcher
+}
+
+// Static serves the files of the dir under the url prefix, it supports range requests, ETag and Last-Modified.
+// If the prefix is "/", the files will be served for the paths that don't match any route.
+func (ctx *ServerContext) Static(prefix, dir string, opts *StaticOptions) *ServerContext {
+	if opts == nil {
+		opts = &StaticOptions{}
+	}
+
+	prefix = "/" + strings.Trim(prefix, "/")
+
+	s := &staticServer{prefix: prefix, dir: dir, opts: opts}
+	if opts.LiveReload != nil {
+		s.reload = newReloadHub()
+		err := s.reload.watch(opts.LiveReload, ctx.closing)
+		if err != nil {
+			ctx.err = err
+			return ctx
+		}
+	}
+
+	if prefix == "/" {
+		ctx.Engine.NoRoute(func(c GinContext) {
+			if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+				s.serve(c, ctx.closing)
+			}
+		})
+	} else {
+		h := func(c GinContext) { s.serve(c, ctx.closing) }
+		ctx.Engine.GET(prefix+"/*filepath", h)
+		ctx.Engine.HEAD(prefix+"/*filepath", h)
+	}
+
+	return ctx
+}
+
+type staticServer struct {
+	prefix string
+	dir    string
+	opts   *StaticOptions
+	reload *reloadHub
+}
+
+func (s *staticServer) serve(c GinContext, closing <-chan utils.Nil) {
+	p := path.Clean("/" + strings.TrimPrefix(c.Request.URL.Path, s.prefix))
+
+	if s.reload != nil {
+		switch p {
+		case "/_live_reload":
+			s.reload.serve(c, closing)
+			return
+		case "/_live_reload.js":
+			c.Data(http.StatusOK, "application/javascript", []byte(fmt.Sprintf(liveReloadJS, path.Join(s.prefix, "_live_reload"))))
+			return
+		}
+	}
+
+	full := filepath.Join(s.dir, filepath.FromSlash(p))
+	info, err := os.Stat(full)
+
+	if err == nil && info.IsDir() {
+		if !strings.HasSuffix(c.Request.URL.Path, "/") {
+			c.Redirect(http.StatusMovedPermanently, c.Request.URL.Path+"/")
+			return
+		}
+
+		index := filepath.Join(full, "index.html")
+		if i, e := os.Stat(index); e == nil && !i.IsDir() {
+			s.file(c, index, i)
+			return
+		}
+
+		if s.opts.Listing {
+			s.list(c, full)
+			return
+		}
+
+		err = os.ErrNotExist
+	}
+
+	if err != nil {
+		index := filepath.Join(s.dir, "index.html")
+		if i, e := os.Stat(index); s.opts.SPA && e == nil && !i.IsDir() {
+			s.file(c, index, i)
+			return
+		}
+
+		c.String(http.StatusNotFound, "404 page not found")
+		return
+	}
+
+	s.file(c, full, info)
+}
+
+func (s *staticServer) file(c GinContext, p string, info os.FileInfo) {
+	f, err := os.Open(p)
+	if err != nil {
+		c.String(http.StatusNotFound, "404 page not found")
+		return
+	}
+	defer func() { _ = f.Close() }()
+
+	// use the strong validator, the ServeContent only matches the If-Range with the strong one
+	c.Header("ETag", fmt.Sprintf(`"%x-%x"`, info.ModTime().UnixNano(), info.Size()))
+	http.ServeContent(c.Writer, c.Request, info.Name(), info.ModTime(), f)
+}
+
+func (s *staticServer) list(c GinContext, dir string) {
+	f, err := os.Open(dir)
+	if err != nil {
+		c.String(http.StatusNotFound, "404 page not found")
+		return
+	}
+	defer func() { _ = f.Close() }()
+
+	list, err := f.Readdir(-1)
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name() < list[j].Name() })
+
+	out := "<pre>\n"
+	for _, info := range list {
+		name := info.Name()
+		if info.IsDir() {
+			name += "/"
+		}
+		u := url.URL{Path: name}
+		out += fmt.Sprintf("<a href=\"%s\">%s</a>\n", u.String(), html.EscapeString(name))
+	}
+	out += "</pre>\n"
+
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(out))
+}
+
+const liveReloadJS = `new EventSource(%q).addEventListener("reload", function () { location.reload() })`
+
+type reloadHub struct {
+	lock sync.Mutex
+	subs map[chan utils.Nil]utils.Nil
+}
+
+func newReloadHub() *reloadHub {
+	return &reloadHub{subs: map[chan utils.Nil]utils.Nil{}}
+}
+
+// watch polls the files matched by the m, broadcasts to the subscribers when any of them changes
+func (h *reloadHub) watch(m *gos.Matcher, closing <-chan utils.Nil) error {
+	list, err := gos.Walk().Matcher(m).List()
+	if err != nil {
+		return err
+	}
+
+	w := watcher.New()
+	for _, p := range list {
+		_ = w.Add(p)
+		_ = w.Add(filepath.Dir(p))
+	}
+
+	go func() {
+		for {
+			select {
+			case e := <-w.Event:
+				matched, _, _ := m.Match(e.Path, e.IsDir())
+				if !matched {
+					continue
+				}
+				if e.Op == watcher.Create && !e.IsDir() {
+					_ = w.Add(e.Path)
+				}
+				h.broadcast()
+			case <-w.Error:
+			case <-closing:
+				w.Close()
+				return
+			case <-w.Closed:
+				return
+			}
+		}
+	}()
+
+	go func() { _ = w.Start(100 * time.Millisecond) }()
+
+	return nil
+}
+
+func (h *reloadHub) broadcast() {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	for ch := range h.subs {
+		select {
+		case ch <- utils.Nil{}:
+		default:
+		}
+	}
+}
+
+func (h *reloadHub) serve(c GinContext, closing <-chan utils.Nil) {
+	ch := make(chan utils.Nil, 1)
+
+	h.lock.Lock()
+	h.subs[ch] = utils.Nil{}
+	h.lock.Unlock()
+
+	defer func() {
+		h.lock.Lock()
+		delete(h.subs, ch)
+		h.lock.Unlock()
+	}()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	for {
+		select {
+		case <-ch:
+			_, _ = c.Writer.WriteString("event: reload\ndata: \n\n")
+			c.Writer.Flush()
+		case <-c.Request.Context().Done():
+			return
+		case <-closing:
+			return
+		}
+	}
+}
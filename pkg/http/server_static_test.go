@@ -0,0 +1,114 @@
+package http_test
+
+import (
+	"bufio"
+	"context"
+	"path/filepath"
+	"strings"
+
+	"github.com/ysmood/kit"
+)
+
+func (s *RequestSuite) staticDir() string {
+	dir, err := filepath.Abs(filepath.Join("tmp", kit.RandString(8)))
+	kit.E(err)
+
+	kit.E(kit.OutputFile(filepath.Join(dir, "index.html"), "index", nil))
+	kit.E(kit.OutputFile(filepath.Join(dir, "a.txt"), "0123456789", nil))
+	kit.E(kit.OutputFile(filepath.Join(dir, "sub", "b.txt"), "b", nil))
+	kit.E(kit.OutputFile(filepath.Join(dir, "sub", "c d.txt"), "c", nil))
+
+	return dir
+}
+
+func (s *RequestSuite) TestServerStatic() {
+	defer func() { _ = kit.Remove("tmp") }()
+
+	dir := s.staticDir()
+
+	server, url := s.server()
+	server.Static("/static", dir, &kit.StaticOptions{Listing: true})
+	server.Engine.GET("/api", func(c kit.GinContext) {
+		c.String(200, "api")
+	})
+
+	s.Equal("api", kit.Req(url+"/api").MustString())
+	s.Equal("index", kit.Req(url+"/static/").MustString())
+	s.Equal("0123456789", kit.Req(url+"/static/a.txt").MustString())
+	s.Equal("b", kit.Req(url+"/static/sub/../sub/b.txt").MustString())
+	s.Equal(404, kit.Req(url+"/static/not-exists").MustResponse().StatusCode)
+
+	list := kit.Req(url + "/static/sub").MustString()
+	s.Equal("<pre>\n<a href=\"b.txt\">b.txt</a>\n<a href=\"c%20d.txt\">c d.txt</a>\n</pre>\n", list)
+
+	// range
+	req := kit.Req(url+"/static/a.txt").Header("Range", "bytes=2-4")
+	s.Equal("234", req.MustString())
+	s.Equal(206, req.MustResponse().StatusCode)
+
+	// cache
+	res := kit.Req(url + "/static/a.txt").MustResponse()
+	etag := res.Header.Get("ETag")
+	s.Regexp(`^"[0-9a-f]+-a"$`, etag)
+	s.NotEmpty(res.Header.Get("Last-Modified"))
+
+	s.Equal(304, kit.Req(url+"/static/a.txt").Header("If-None-Match", etag).MustResponse().StatusCode)
+	s.Equal(304, kit.Req(url+"/static/a.txt").Header(
+		"If-Modified-Since", res.Header.Get("Last-Modified"),
+	).MustResponse().StatusCode)
+
+	req = kit.Req(url+"/static/a.txt").Header("Range", "bytes=2-4", "If-Range", etag)
+	s.Equal("234", req.MustString())
+	req = kit.Req(url+"/static/a.txt").Header("Range", "bytes=2-4", "If-Range", `"other"`)
+	s.Equal("0123456789", req.MustString())
+}
+
+func (s *RequestSuite) TestServerStaticSPA() {
+	defer func() { _ = kit.Remove("tmp") }()
+
+	dir := s.staticDir()
+
+	server, url := s.server()
+	server.Static("/", dir, &kit.StaticOptions{SPA: true})
+	server.Engine.GET("/api", func(c kit.GinContext) {
+		c.String(200, "api")
+	})
+
+	s.Equal("api", kit.Req(url+"/api").MustString())
+	s.Equal("index", kit.Req(url).MustString())
+	s.Equal("index", kit.Req(url+"/users/1").MustString())
+	s.Equal("index", kit.Req(url+"/sub/").MustString())
+	s.Equal("b", kit.Req(url+"/sub/b.txt").MustString())
+	s.Equal(404, kit.Req(url+"/api").Post().MustResponse().StatusCode)
+
+	res := kit.Req(url + "/sub").MustResponse()
+	s.Equal("index", kit.Req(url+"/sub").MustString())
+	s.Equal("/sub/", res.Request.URL.Path)
+}
+
+func (s *RequestSuite) TestServerStaticLiveReload() {
+	defer func() { _ = kit.Remove("tmp") }()
+
+	dir := s.staticDir()
+
+	server, url := s.server()
+	server.Static("/", dir, &kit.StaticOptions{
+		LiveReload: kit.NewMatcher(dir, []string{"**/*.txt"}),
+	})
+
+	// stop the watcher before the dir is removed
+	defer server.MustShutdown(context.Background())
+
+	s.Contains(kit.Req(url+"/_live_reload.js").MustString(), `EventSource("/_live_reload")`)
+
+	body, err := kit.Req(url + "/_live_reload").Response()
+	s.Nil(err)
+	defer func() { _ = body.Body.Close() }()
+	r := bufio.NewReader(body.Body)
+
+	kit.E(kit.OutputFile(filepath.Join(dir, "sub", "b.txt"), "new b", nil))
+
+	line, err := r.ReadString('\n')
+	s.Nil(err)
+	s.Equal("event: reload", strings.TrimSpace(line))
+}
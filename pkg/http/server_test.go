@@ -0,0 +1,124 @@
+package http_test
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/ysmood/kit"
+)
+
+func (s *RequestSuite) TestServerShutdown() {
+	server := kit.MustServer(":0")
+	url := "http://" + server.Listener.Addr().String()
+
+	started := false
+	shutdown := false
+	server.OnStart(func() { started = true })
+	server.OnShutdown(func() { shutdown = true })
+
+	wait := make(chan kit.Nil)
+	server.Engine.GET("/", func(c kit.GinContext) {
+		close(wait)
+		time.Sleep(50 * time.Millisecond)
+		c.String(200, "ok")
+	})
+
+	done := make(chan error)
+	go func() { done <- server.Do() }()
+	<-server.Ready()
+	s.True(started)
+
+	res := make(chan string)
+	go func() { res <- kit.Req(url).MustString() }()
+	<-wait
+
+	server.MustShutdown(context.Background())
+
+	s.Equal("ok", <-res)
+	s.Nil(<-done)
+	s.True(shutdown)
+	s.Error(kit.Req(url).Do())
+
+	// calling it again returns the same result
+	s.Nil(server.Shutdown(context.Background()))
+}
+
+func (s *RequestSuite) TestServerShutdownTimeout() {
+	server := kit.MustServer(":0")
+	url := "http://" + server.Listener.Addr().String()
+
+	wait := make(chan kit.Nil)
+	server.Engine.GET("/", func(c kit.GinContext) {
+		close(wait)
+		time.Sleep(time.Second)
+	})
+
+	done := make(chan error)
+	go func() { done <- server.Do() }()
+	<-server.Ready()
+
+	go func() { _ = kit.Req(url).Do() }()
+	<-wait
+
+	c, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	s.Equal(context.DeadlineExceeded, server.Shutdown(c))
+	s.Equal(context.DeadlineExceeded, <-done)
+}
+
+func (s *RequestSuite) TestServerCloseListener() {
+	server := kit.MustServer("127.0.0.1:0")
+
+	count := 0
+	server.OnStart(func() { count++ })
+
+	done := make(chan error)
+	go func() { done <- server.Do() }()
+	<-server.Ready()
+
+	// the baseline way to stop the server
+	kit.E(server.Listener.Close())
+	s.Error(<-done)
+
+	// calling it again won't panic or start the server again
+	s.Error(server.Do())
+	s.Equal(1, count)
+}
+
+func (s *RequestSuite) TestServerShutdownBeforeDo() {
+	server := kit.MustServer(":0")
+	s.Nil(server.Shutdown(context.Background()))
+	s.Nil(server.Do())
+}
+
+func (s *RequestSuite) TestServerShutdownOnSignal() {
+	if runtime.GOOS == "windows" {
+		// same as the TestWaitSignal of the os package
+		return
+	}
+
+	server := kit.MustServer(":0").ShutdownOnSignal(time.Second, os.Interrupt)
+
+	shutdown := make(chan kit.Nil)
+	server.OnShutdown(func() { close(shutdown) })
+
+	done := make(chan error)
+	go func() { done <- server.Do() }()
+	<-server.Ready()
+
+	// wait for the signal handler to be registered
+	time.Sleep(100 * time.Millisecond)
+	kit.E(kit.SendSigInt(os.Getpid()))
+
+	<-shutdown
+	s.Nil(<-done)
+}
+
+func (s *RequestSuite) TestServerDoErr() {
+	server := kit.MustServer(":0")
+	_ = server.Listener.Close()
+	s.Error(server.Do())
+}
@@ -0,0 +1,125 @@
+package http
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"time"
+)
+
+// TLS makes the server serve https with the cert and key files
+func (ctx *ServerContext) TLS(certFile, keyFile string) *ServerContext {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		ctx.err = err
+		return ctx
+	}
+
+	ctx.tlsConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+	return ctx
+}
+
+// SelfSigned makes the server serve https with an in-memory cert for the hosts, the cert is signed by
+// a generated CA, use CA to get the pem of it. The default hosts are localhost, 127.0.0.1 and ::1.
+func (ctx *ServerContext) SelfSigned(hosts ...string) *ServerContext {
+	ca, certPEM, keyPEM, err := GenerateCert(hosts...)
+	if err != nil {
+		ctx.err = err
+		return ctx
+	}
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		ctx.err = err
+		return ctx
+	}
+
+	ctx.ca = ca
+	ctx.tlsConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+	return ctx
+}
+
+// CA returns the pem of the CA generated by SelfSigned, such as kit.Req(u).CA(server.CA())
+func (ctx *ServerContext) CA() []byte {
+	return ctx.ca
+}
+
+// GenerateCert generates a CA and a cert for the hosts signed by the CA, all of them are pem encoded.
+// The hosts can be domains or ips, the default hosts are localhost, 127.0.0.1 and ::1.
+func GenerateCert(hosts ...string) (ca, cert, key []byte, err error) {
+	if len(hosts) == 0 {
+		hosts = []string{"localhost", "127.0.0.1", "::1"}
+	}
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return
+	}
+
+	caTpl, err := certTemplate("kit CA")
+	if err != nil {
+		return
+	}
+	caTpl.IsCA = true
+	caTpl.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+	caTpl.BasicConstraintsValid = true
+
+	caDer, err := x509.CreateCertificate(rand.Reader, caTpl, caTpl, &caKey.PublicKey, caKey)
+	if err != nil {
+		return
+	}
+
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return
+	}
+
+	leafTpl, err := certTemplate(hosts[0])
+	if err != nil {
+		return
+	}
+	leafTpl.KeyUsage = x509.KeyUsageDigitalSignature
+	leafTpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	for _, h := range hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			leafTpl.IPAddresses = append(leafTpl.IPAddresses, ip)
+		} else {
+			leafTpl.DNSNames = append(leafTpl.DNSNames, h)
+		}
+	}
+
+	leafDer, err := x509.CreateCertificate(rand.Reader, leafTpl, caTpl, &leafKey.PublicKey, caKey)
+	if err != nil {
+		return
+	}
+
+	keyDer, err := x509.MarshalECPrivateKey(leafKey)
+	if err != nil {
+		return
+	}
+
+	ca = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDer})
+	cert = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leafDer})
+	key = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+	return
+}
+
+func certTemplate(name string) (*x509.Certificate, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+
+	return &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().AddDate(1, 0, 0),
+	}, nil
+}
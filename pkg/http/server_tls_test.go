@@ -0,0 +1,54 @@
+package http_test
+
+import (
+	"net"
+	"path/filepath"
+
+	"github.com/ysmood/kit"
+)
+
+func (s *RequestSuite) TestServerSelfSigned() {
+	server := kit.MustServer("127.0.0.1:0").SelfSigned()
+	server.Engine.GET("/", func(c kit.GinContext) {
+		c.String(200, "ok")
+	})
+	go server.MustDo()
+	<-server.Ready()
+
+	url := "https://" + server.Listener.Addr().String()
+
+	s.Error(kit.Req(url).Do())
+	s.Equal("ok", kit.Req(url).CA(server.CA()).MustString())
+}
+
+func (s *RequestSuite) TestServerTLS() {
+	defer func() { _ = kit.Remove("tmp") }()
+
+	ca, cert, key, err := kit.GenerateCert("localhost")
+	s.Nil(err)
+
+	dir := filepath.Join("tmp", kit.RandString(8))
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	kit.E(kit.OutputFile(certFile, cert, nil))
+	kit.E(kit.OutputFile(keyFile, key, nil))
+
+	server := kit.MustServer("127.0.0.1:0").TLS(certFile, keyFile)
+	server.Engine.GET("/", func(c kit.GinContext) {
+		c.String(200, "ok")
+	})
+	go server.MustDo()
+	<-server.Ready()
+
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	url := "https://localhost:" + port
+
+	s.Equal("ok", kit.Req(url).CA(ca).MustString())
+
+	// the cert isn't issued for the ip
+	s.Error(kit.Req("https://" + server.Listener.Addr().String()).CA(ca).Do())
+}
+
+func (s *RequestSuite) TestServerTLSErr() {
+	s.Error(kit.MustServer(":0").TLS("not-exists", "not-exists").Do())
+}
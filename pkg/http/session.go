@@ -0,0 +1,145 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SessionContext holds the settings and the cookie jar shared by the requests it spawns.
+// It's safe to spawn and send requests concurrently, the connection pool is shared too.
+type SessionContext struct {
+	lock sync.RWMutex
+
+	client      *http.Client
+	baseURL     string
+	header      http.Header
+	timeout     time.Duration
+	middlewares []Middleware
+	err         error
+}
+
+// Session creates a session, all the requests spawned by it will share the cookie jar and the
+// connection pool
+func Session() *SessionContext {
+	return &SessionContext{
+		client: newClient(),
+		header: http.Header{},
+	}
+}
+
+// BaseURL sets the url prefix of the relative urls passed to Req
+func (s *SessionContext) BaseURL(u string) *SessionContext {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.baseURL = u
+	return s
+}
+
+// Header appends the default request header, example Header(k, v, k, v ...)
+func (s *SessionContext) Header(params ...string) *SessionContext {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for i := 0; i < len(params); i += 2 {
+		s.header.Add(params[i], params[i+1])
+	}
+	return s
+}
+
+// Timeout sets the default timeout of each request
+func (s *SessionContext) Timeout(d time.Duration) *SessionContext {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.timeout = d
+	return s
+}
+
+// Use appends middlewares to each request
+func (s *SessionContext) Use(list ...Middleware) *SessionContext {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.middlewares = append(s.middlewares, list...)
+	return s
+}
+
+// Client sets the http client of the session
+func (s *SessionContext) Client(c *http.Client) *SessionContext {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.client = c
+	return s
+}
+
+// GetClient gets the http client of the session
+func (s *SessionContext) GetClient() *http.Client {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.client
+}
+
+// Proxy sets the proxy of the session
+func (s *SessionContext) Proxy(u string) *SessionContext {
+	return s.transport(proxyOption(u))
+}
+
+// CA appends the PEM encoded certificates to the trusted certificate authorities of the session
+func (s *SessionContext) CA(pem []byte) *SessionContext {
+	return s.transport(caOption(pem))
+}
+
+// ClientCert sets the PEM encoded certificate and key files for the TLS client authentication
+func (s *SessionContext) ClientCert(certFile, keyFile string) *SessionContext {
+	return s.transport(clientCertOption(certFile, keyFile))
+}
+
+// Insecure skips the verification of the server's certificate, only use it for testing
+func (s *SessionContext) Insecure() *SessionContext {
+	return s.transport(insecureOption())
+}
+
+// UnixSocket sends the requests of the session over the unix domain socket
+func (s *SessionContext) UnixSocket(path string) *SessionContext {
+	return s.transport(unixSocketOption(path))
+}
+
+// the transport of the session is derived once, so that its requests share the connection pool
+func (s *SessionContext) transport(opt transportOption) *SessionContext {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	c, err := withTransport(s.client, []transportOption{opt})
+	if err != nil {
+		s.err = err
+		return s
+	}
+	s.client = c
+
+	return s
+}
+
+// Req creates a request with the settings of the session, if the u is relative it will be
+// prefixed with the base url
+func (s *SessionContext) Req(u string) *ReqContext {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	ctx := Req(s.resolve(u)).Client(s.client).Headers(s.header.Clone()).Timeout(s.timeout)
+	ctx.middlewares = append([]Middleware{}, s.middlewares...)
+	ctx.err = s.err
+	return ctx
+}
+
+func (s *SessionContext) resolve(u string) string {
+	if s.baseURL == "" || strings.Contains(u, "://") {
+		return u
+	}
+
+	return strings.TrimRight(s.baseURL, "/") + "/" + strings.TrimLeft(u, "/")
+}
@@ -0,0 +1,79 @@
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ysmood/kit"
+)
+
+func (s *RequestSuite) TestSession() {
+	path, url := s.path()
+
+	s.router.GET(path, func(c kit.GinContext) {
+		cookie, _ := c.Cookie("t")
+		c.SetCookie("t", "val", 3600, "", "", false, true)
+		c.String(200, c.GetHeader("a")+c.GetHeader("b")+cookie)
+	})
+
+	session := kit.Session().BaseURL(strings.TrimSuffix(url, path)+"/").Header("a", "1")
+
+	s.Equal("1", session.Req(path).MustString())
+	s.Equal("12val", session.Req(path).Header("b", "2").MustString())
+
+	// the default header should not be affected by the request
+	s.Equal("1val", session.Req(url).MustString())
+}
+
+func (s *RequestSuite) TestSessionConcurrent() {
+	path, url := s.path()
+
+	s.router.GET(path, func(c kit.GinContext) {
+		c.String(200, c.GetHeader("X-Request-Id"))
+	})
+
+	session := kit.Session().Use(kit.RequestID()).Timeout(time.Minute)
+
+	list := []func(){}
+	for i := 0; i < 10; i++ {
+		list = append(list, func() {
+			s.Len(session.Req(url).MustString(), 16)
+		})
+	}
+	kit.All(list...)()
+}
+
+func (s *RequestSuite) TestSessionTimeout() {
+	// the handler is still running after the timeout, use a standalone server and
+	// wait for the handler to finish, so that it won't race with the other tests
+	server, url := s.server()
+	defer server.MustShutdown(context.Background())
+
+	server.Engine.GET("/", func(c kit.GinContext) {
+		select {
+		case <-c.Request.Context().Done():
+		case <-time.After(time.Second):
+		}
+	})
+
+	s.Error(kit.Session().Timeout(time.Millisecond).Req(url).Do())
+}
+
+func (s *RequestSuite) TestSessionProxy() {
+	path, url := s.path()
+
+	s.router.GET(path, func(c kit.GinContext) {
+		kit.E(c.Writer.WriteString(c.Request.URL.String()))
+	})
+
+	client := &http.Client{}
+	session := kit.Session().Client(client).Proxy(url)
+	s.NotSame(client, session.GetClient())
+
+	target := "http://test.com" + path
+	s.Equal(target, session.Req(target).MustString())
+
+	s.Error(kit.Session().Proxy("0://abc.com").Req(target).Do())
+}
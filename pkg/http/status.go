@@ -0,0 +1,83 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"unicode/utf8"
+)
+
+// the max length of the body that StatusError keeps
+const statusErrorBodyLimit = 1024
+
+// StatusError is returned when the response status code is not expected
+type StatusError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Status     string
+	Header     http.Header
+
+	// Body is truncated if it's too long
+	Body string
+}
+
+// Error interface
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s %s: unexpected status %s: %s", e.Method, e.URL, e.Status, e.Body)
+}
+
+// ExpectStatus makes Do, Bytes, JSON, etc return StatusError if the status code of the response
+// is not one of the codes
+func (ctx *ReqContext) ExpectStatus(codes ...int) *ReqContext {
+	ctx.expectStatus = func(code int) bool {
+		for _, c := range codes {
+			if c == code {
+				return true
+			}
+		}
+		return false
+	}
+	return ctx
+}
+
+// ExpectSuccess is the same as the ExpectStatus, but expects status code 2xx
+func (ctx *ReqContext) ExpectSuccess() *ReqContext {
+	ctx.expectStatus = func(code int) bool {
+		return code >= 200 && code < 300
+	}
+	return ctx
+}
+
+func (ctx *ReqContext) checkStatus() error {
+	res := ctx.response
+	if ctx.expectStatus == nil || ctx.expectStatus(res.StatusCode) {
+		return nil
+	}
+
+	if ctx.resBytes == nil {
+		var err error
+		ctx.resBytes, err = readBody(res.Body)
+		if err != nil {
+			return err
+		}
+	}
+
+	body := string(ctx.resBytes)
+	if len(body) > statusErrorBodyLimit {
+		// don't cut a multi-byte character in half
+		end := statusErrorBodyLimit
+		for end > 0 && !utf8.RuneStart(body[end]) {
+			end--
+		}
+		body = body[:end] + "..."
+	}
+
+	return &StatusError{
+		Method:     ctx.request.Method,
+		URL:        ctx.request.URL.String(),
+		StatusCode: res.StatusCode,
+		Status:     res.Status,
+		Header:     res.Header,
+		Body:       body,
+	}
+}
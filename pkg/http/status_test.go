@@ -0,0 +1,61 @@
+package http_test
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/ysmood/kit"
+)
+
+func (s *RequestSuite) TestExpectStatus() {
+	path, url := s.path()
+
+	s.router.GET(path, func(c kit.GinContext) {
+		c.String(201, "ok")
+	})
+
+	s.Equal("ok", kit.Req(url).ExpectStatus(200, 201).MustString())
+	s.Equal("ok", kit.Req(url).ExpectSuccess().MustString())
+
+	c := kit.Req(url).ExpectStatus(200)
+	err := c.Do()
+	s.EqualError(err, "GET "+url+": unexpected status 201 Created: ok")
+
+	var statusErr *kit.StatusError
+	s.True(errors.As(err, &statusErr))
+	s.Equal(201, statusErr.StatusCode)
+	s.Equal("text/plain; charset=utf-8", statusErr.Header.Get("Content-Type"))
+
+	_, err = c.Bytes()
+	s.Equal(statusErr, err)
+
+	_, err = c.JSON()
+	s.Equal(statusErr, err)
+}
+
+func (s *RequestSuite) TestExpectSuccess() {
+	path, url := s.path()
+
+	s.router.GET(path, func(c kit.GinContext) {
+		c.String(500, strings.Repeat("x", 2000))
+	})
+
+	s.PanicsWithError(
+		"GET "+url+": unexpected status 500 Internal Server Error: "+strings.Repeat("x", 1024)+"...",
+		func() { kit.Req(url).ExpectSuccess().MustDo() },
+	)
+}
+
+func (s *RequestSuite) TestExpectSuccessUTF8() {
+	path, url := s.path()
+
+	s.router.GET(path, func(c kit.GinContext) {
+		c.String(500, "xx"+strings.Repeat("中", 1000))
+	})
+
+	// the 1024th byte is in the middle of a 3-byte character
+	s.PanicsWithError(
+		"GET "+url+": unexpected status 500 Internal Server Error: xx"+strings.Repeat("中", 340)+"...",
+		func() { kit.Req(url).ExpectSuccess().MustDo() },
+	)
+}
@@ -0,0 +1,159 @@
+package http
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	gos "github.com/ysmood/kit/pkg/os"
+)
+
+// Lines sends request, calls fn with each line of the response body without buffering the whole body.
+// The line doesn't contain the line ending. If fn returns error the reading will stop.
+func (ctx *ReqContext) Lines(fn func(line string) error) error {
+	body, err := ctx.stream()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = body.Close() }()
+
+	r := bufio.NewReader(body)
+	for {
+		line, err := r.ReadString('\n')
+		if line != "" || err == nil {
+			fnErr := fn(strings.TrimRight(line, "\r\n"))
+			if fnErr != nil {
+				return fnErr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// SSEEvent is a record of the server-sent events
+type SSEEvent struct {
+	ID    string
+	Event string
+	Data  string
+
+	// Retry is the reconnection time in milliseconds, 0 means not set
+	Retry int
+}
+
+// SSE sends request, calls fn with each event of the server-sent events stream.
+// If fn returns error the reading will stop.
+func (ctx *ReqContext) SSE(fn func(e *SSEEvent) error) error {
+	if ctx.header.Get("Accept") == "" {
+		ctx.header.Set("Accept", "text/event-stream")
+	}
+
+	id := ""
+	e := &SSEEvent{}
+	data := []string{}
+
+	return ctx.Lines(func(line string) error {
+		if line == "" {
+			if len(data) == 0 {
+				e = &SSEEvent{}
+				return nil
+			}
+
+			e.ID = id
+			e.Data = strings.Join(data, "\n")
+			err := fn(e)
+
+			e = &SSEEvent{}
+			data = []string{}
+			return err
+		}
+
+		if strings.HasPrefix(line, ":") {
+			return nil
+		}
+
+		field, value := line, ""
+		if i := strings.Index(line, ":"); i > -1 {
+			field, value = line[:i], strings.TrimPrefix(line[i+1:], " ")
+		}
+
+		switch field {
+		case "id":
+			id = value
+		case "event":
+			e.Event = value
+		case "data":
+			data = append(data, value)
+		case "retry":
+			if n, err := strconv.Atoi(value); err == nil {
+				e.Retry = n
+			}
+		}
+		return nil
+	})
+}
+
+// Download sends request, streams the response body to the file.
+// The progress will be called after each write with the written size and the total size,
+// the total is -1 if it's unknown. The progress can be nil.
+func (ctx *ReqContext) Download(path string, progress func(written, total int64)) error {
+	body, err := ctx.stream()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = body.Close() }()
+
+	err = gos.Mkdir(filepath.Dir(path), nil)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = f
+	if progress != nil {
+		w = &progressWriter{w: f, total: ctx.response.ContentLength, progress: progress}
+	}
+
+	_, err = io.Copy(w, body)
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+type progressWriter struct {
+	w        io.Writer
+	written  int64
+	total    int64
+	progress func(written, total int64)
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.written += int64(n)
+	w.progress(w.written, w.total)
+	return n, err
+}
+
+// stream sends request and returns the response body for streaming
+func (ctx *ReqContext) stream() (io.ReadCloser, error) {
+	res, err := ctx.Response()
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Body, nil
+}
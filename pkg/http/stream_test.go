@@ -0,0 +1,122 @@
+package http_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"time"
+
+	"github.com/ysmood/kit"
+)
+
+func (s *RequestSuite) TestLines() {
+	path, url := s.path()
+
+	s.router.GET(path, func(c kit.GinContext) {
+		for _, l := range []string{"a\n", "b\r\n", "\n", "c"} {
+			_, _ = c.Writer.WriteString(l)
+			c.Writer.Flush()
+		}
+	})
+
+	lines := []string{}
+	kit.E(kit.Req(url).Lines(func(l string) error {
+		lines = append(lines, l)
+		return nil
+	}))
+
+	s.Equal([]string{"a", "b", "", "c"}, lines)
+}
+
+func (s *RequestSuite) TestLinesStop() {
+	path, url := s.path()
+
+	s.router.GET(path, func(c kit.GinContext) {
+		c.String(200, "a\nb\n")
+	})
+
+	err := kit.Req(url).Lines(func(l string) error {
+		return io.ErrUnexpectedEOF
+	})
+
+	s.Equal(io.ErrUnexpectedEOF, err)
+	s.Error(kit.Req("").Lines(nil))
+}
+
+func (s *RequestSuite) TestLinesCancel() {
+	path, url := s.path()
+
+	s.router.GET(path, func(c kit.GinContext) {
+		_, _ = c.Writer.WriteString("a\n")
+		c.Writer.Flush()
+		<-c.Request.Context().Done()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err := kit.Req(url).Context(ctx).Lines(func(l string) error {
+		cancel()
+		return nil
+	})
+
+	s.True(errors.Is(err, context.Canceled))
+}
+
+func (s *RequestSuite) TestLinesTimeout() {
+	path, url := s.path()
+
+	s.router.GET(path, func(c kit.GinContext) {
+		_, _ = c.Writer.WriteString("a\n")
+		c.Writer.Flush()
+		<-c.Request.Context().Done()
+	})
+
+	err := kit.Req(url).Timeout(100 * time.Millisecond).Lines(func(l string) error {
+		return nil
+	})
+
+	s.True(errors.Is(err, context.DeadlineExceeded))
+}
+
+func (s *RequestSuite) TestSSE() {
+	path, url := s.path()
+
+	s.router.GET(path, func(c kit.GinContext) {
+		_, _ = c.Writer.WriteString(c.GetHeader("Accept") + "\n\n")
+		_, _ = c.Writer.WriteString(": comment\nid: 1\nevent: a\ndata: x\ndata:y\nretry: 10\n\n")
+		_, _ = c.Writer.WriteString("data\n\nevent: empty\n\n")
+	})
+
+	list := []kit.SSEEvent{}
+	kit.E(kit.Req(url).SSE(func(e *kit.SSEEvent) error {
+		list = append(list, *e)
+		return nil
+	}))
+
+	s.Equal([]kit.SSEEvent{
+		{ID: "1", Event: "a", Data: "x\ny", Retry: 10},
+		{ID: "1", Data: ""},
+	}, list)
+}
+
+func (s *RequestSuite) TestDownload() {
+	path, url := s.path()
+
+	s.router.GET(path, func(c kit.GinContext) {
+		c.String(200, "file")
+	})
+
+	p := filepath.Join("tmp", kit.RandString(8), "file")
+	defer func() { _ = kit.Remove("tmp") }()
+
+	var written, total int64
+	kit.E(kit.Req(url).Download(p, func(w, t int64) {
+		written, total = w, t
+	}))
+
+	s.Equal("file", kit.E(kit.ReadString(p))[0])
+	s.Equal(int64(4), written)
+	s.Equal(int64(4), total)
+
+	s.Error(kit.Req("").Download(p, nil))
+}
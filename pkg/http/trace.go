@@ -0,0 +1,169 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http/httptrace"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ysmood/kit/pkg/utils"
+)
+
+// Timing is the durations of the phases of a request, the phase that didn't happen will be zero,
+// such as when the connection is reused there will be no DNS, Connect and TLS
+type Timing struct {
+	Start time.Time
+
+	DNS     time.Duration
+	Connect time.Duration
+	TLS     time.Duration
+
+	// Wait is the duration from the request is written to the first response byte
+	Wait time.Duration
+
+	// TTFB is the duration from the start to the first response byte
+	TTFB time.Duration
+
+	// Total is the duration from the start to the response headers are received
+	Total time.Duration
+
+	// Limit is the duration waiting for the Limiter
+	Limit time.Duration
+
+	Reused bool
+
+	lock   sync.Mutex
+	phases map[string][2]time.Time
+}
+
+// Trace records the timing of the request, get it via the Timing after the request is sent.
+// If the request is retried, only the last attempt is recorded. The reading of the response body
+// is not included. If log is true, the waterfall of the timing will be printed via utils.Log.
+func (ctx *ReqContext) Trace(log bool) *ReqContext {
+	ctx.timing = &Timing{}
+	ctx.traceLog = log
+	return ctx
+}
+
+// Timing returns the timing of the request, it's nil if the Trace is not enabled
+func (ctx *ReqContext) Timing() *Timing {
+	return ctx.timing
+}
+
+func (t *Timing) begin(name string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.phases[name] = [2]time.Time{time.Now()}
+}
+
+func (t *Timing) end(name string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	p := t.phases[name]
+	p[1] = time.Now()
+	t.phases[name] = p
+}
+
+func (t *Timing) duration(name string) time.Duration {
+	p, has := t.phases[name]
+	if !has || p[1].IsZero() {
+		return 0
+	}
+	return p[1].Sub(p[0])
+}
+
+type timingKey struct{}
+
+func timingFrom(c context.Context) *Timing {
+	t, _ := c.Value(timingKey{}).(*Timing)
+	return t
+}
+
+func (t *Timing) withTrace(c context.Context) context.Context {
+	c = context.WithValue(c, timingKey{}, t)
+	return httptrace.WithClientTrace(c, &httptrace.ClientTrace{
+		DNSStart:          func(httptrace.DNSStartInfo) { t.begin("dns") },
+		DNSDone:           func(httptrace.DNSDoneInfo) { t.end("dns") },
+		ConnectStart:      func(_, _ string) { t.begin("connect") },
+		ConnectDone:       func(_, _ string, _ error) { t.end("connect") },
+		TLSHandshakeStart: func() { t.begin("tls") },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { t.end("tls") },
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.lock.Lock()
+			defer t.lock.Unlock()
+			t.Reused = info.Reused
+		},
+		WroteRequest:         func(httptrace.WroteRequestInfo) { t.begin("wait") },
+		GotFirstResponseByte: func() { t.end("wait") },
+	})
+}
+
+// reset before each attempt of the retries, so that the phases of the failed attempts won't add up
+func (t *Timing) reset() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.phases = map[string][2]time.Time{}
+	t.Start = time.Now()
+	t.Reused = false
+}
+
+func (t *Timing) done() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.Limit = t.duration("limit")
+	t.DNS = t.duration("dns")
+	t.Connect = t.duration("connect")
+	t.TLS = t.duration("tls")
+	t.Wait = t.duration("wait")
+	if w, has := t.phases["wait"]; has && !w[1].IsZero() {
+		t.TTFB = w[1].Sub(t.Start)
+	}
+	t.Total = time.Since(t.Start)
+}
+
+// Waterfall renders the timing as a text waterfall chart
+func (t *Timing) Waterfall() string {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	const width = 40
+	scale := float64(width) / float64(t.Total)
+	if t.Total <= 0 {
+		scale = 0
+	}
+
+	out := []string{}
+	for _, name := range []string{"limit", "dns", "connect", "tls", "wait"} {
+		p, has := t.phases[name]
+		if !has || p[1].IsZero() {
+			continue
+		}
+
+		from := int(float64(p[0].Sub(t.Start)) * scale)
+		l := int(float64(p[1].Sub(p[0]))*scale) + 1
+		if from+l > width {
+			l = width - from
+		}
+		if l < 0 {
+			l = 0
+		}
+		bar := strings.Repeat(" ", from) + strings.Repeat("=", l)
+
+		out = append(out, fmt.Sprintf("%-8s|%-*s| %v", name, width, bar, p[1].Sub(p[0])))
+	}
+	out = append(out, fmt.Sprintf("%-8s|%-*s| %v", "total", width, strings.Repeat("=", width), t.Total))
+
+	return strings.Join(out, "\n")
+}
+
+func (ctx *ReqContext) logTrace() {
+	if !ctx.traceLog {
+		return
+	}
+	utils.Log(utils.C("trace", "cyan"), ctx.request.Method, ctx.request.URL.String(), "\n"+ctx.timing.Waterfall())
+}
@@ -0,0 +1,47 @@
+package http_test
+
+import (
+	"time"
+
+	"github.com/ysmood/kit"
+)
+
+func (s *RequestSuite) TestTrace() {
+	path, url := s.path()
+
+	s.router.GET(path, func(c kit.GinContext) {
+		time.Sleep(10 * time.Millisecond)
+		c.String(200, "ok")
+	})
+
+	c := kit.Req(url).Trace(true)
+	s.Zero(c.Timing().Total)
+	c.MustDo()
+
+	t := c.Timing()
+	s.False(t.Reused)
+	s.Greater(int64(t.Connect), int64(0))
+	s.GreaterOrEqual(int64(t.Wait), int64(10*time.Millisecond))
+	s.GreaterOrEqual(int64(t.TTFB), int64(t.Wait))
+	s.GreaterOrEqual(int64(t.Total), int64(t.TTFB))
+	s.Equal(time.Duration(0), t.TLS)
+
+	// the bars are positioned relative to the start of Do, the connect may begin a bit later than the start
+	// because of the request building, so its bar can have leading spaces on a slow machine
+	s.Regexp(`(?s)connect +\| *=+ +\| .+\nwait +\| *=+ *\| .+\ntotal +\|=+\| `, t.Waterfall())
+
+	entry := c.MustHAR()
+	s.Equal(float64(-1), entry.Timings.SSL)
+	s.Greater(entry.Timings.Wait, float64(0))
+}
+
+func (s *RequestSuite) TestTraceTLS() {
+	srv := tlsServer(false)
+	defer srv.Close()
+
+	c := kit.Req(srv.URL).Insecure().Trace(false)
+	c.MustDo()
+
+	s.Greater(int64(c.Timing().TLS), int64(0))
+	s.Nil(kit.Req(srv.URL).Timing())
+}
@@ -0,0 +1,170 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ErrNotTransport is returned when a transport option is set but the transport of the client
+// is not *http.Transport
+var ErrNotTransport = errors.New("the client transport is not *http.Transport")
+
+// ErrInvalidCA is returned when no certificate can be parsed from the CA
+var ErrInvalidCA = errors.New("failed to parse the CA certificate")
+
+type transportOption func(*http.Transport) error
+
+// the idle timeout of the connections of the cloned transport if the original one doesn't have it
+const idleConnTimeout = 90 * time.Second
+
+// CA appends the PEM encoded certificates to the trusted certificate authorities of the request
+func (ctx *ReqContext) CA(pem []byte) *ReqContext {
+	return ctx.transport(caOption(pem))
+}
+
+// ClientCert sets the PEM encoded certificate and key files for the TLS client authentication
+func (ctx *ReqContext) ClientCert(certFile, keyFile string) *ReqContext {
+	return ctx.transport(clientCertOption(certFile, keyFile))
+}
+
+// Insecure skips the verification of the server's certificate, only use it for testing
+func (ctx *ReqContext) Insecure() *ReqContext {
+	return ctx.transport(insecureOption())
+}
+
+// UnixSocket sends the request over the unix domain socket, the host of the url will be ignored,
+// such as UnixSocket("/var/run/docker.sock") with url "http://docker/version"
+func (ctx *ReqContext) UnixSocket(path string) *ReqContext {
+	return ctx.transport(unixSocketOption(path))
+}
+
+func (ctx *ReqContext) transport(opt transportOption) *ReqContext {
+	ctx.transportOptions = append(ctx.transportOptions, opt)
+	return ctx
+}
+
+func caOption(pem []byte) transportOption {
+	return func(t *http.Transport) error {
+		conf := tlsConfig(t)
+		if conf.RootCAs == nil {
+			pool, err := x509.SystemCertPool()
+			if err != nil {
+				pool = x509.NewCertPool()
+			}
+			conf.RootCAs = pool
+		} else {
+			// the transport is cloned, but the pool is still shared with the original one
+			conf.RootCAs = conf.RootCAs.Clone()
+		}
+		if !conf.RootCAs.AppendCertsFromPEM(pem) {
+			return ErrInvalidCA
+		}
+		return nil
+	}
+}
+
+func clientCertOption(certFile, keyFile string) transportOption {
+	return func(t *http.Transport) error {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return err
+		}
+		conf := tlsConfig(t)
+		// the cloned config still shares the backing array of the slice with the original one
+		list := make([]tls.Certificate, len(conf.Certificates), len(conf.Certificates)+1)
+		copy(list, conf.Certificates)
+		conf.Certificates = append(list, cert)
+		return nil
+	}
+}
+
+func insecureOption() transportOption {
+	return func(t *http.Transport) error {
+		tlsConfig(t).InsecureSkipVerify = true
+		return nil
+	}
+}
+
+func unixSocketOption(path string) transportOption {
+	return func(t *http.Transport) error {
+		dialer := &net.Dialer{}
+		t.Proxy = nil
+		t.DialContext = func(c context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(c, "unix", path)
+		}
+		return nil
+	}
+}
+
+func proxyOption(u string) transportOption {
+	return func(t *http.Transport) error {
+		proxyURL, err := url.Parse(u)
+		if err != nil {
+			return err
+		}
+		t.Proxy = http.ProxyURL(proxyURL)
+		return nil
+	}
+}
+
+func (ctx *ReqContext) applyTransport() error {
+	if len(ctx.transportOptions) == 0 {
+		return nil
+	}
+
+	c, err := withTransport(ctx.client, ctx.transportOptions)
+	if err != nil {
+		return err
+	}
+
+	// the options are applied, the clones of ctx, such as the next pages of Paginate,
+	// will reuse the client and its connection pool
+	ctx.client = c
+	ctx.transportOptions = nil
+	return nil
+}
+
+// withTransport clones the client and its transport, then applies the options to the new transport,
+// so that the client that may be shared won't be modified.
+// Each call creates a new connection pool, use the Session to share the pool between requests.
+func withTransport(client *http.Client, options []transportOption) (*http.Client, error) {
+	var t *http.Transport
+	switch rt := client.Transport.(type) {
+	case nil:
+		t = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		t = rt.Clone()
+	default:
+		return nil, ErrNotTransport
+	}
+
+	// the clone won't be reachable once the request is done, don't keep its idle connections forever
+	if t.IdleConnTimeout == 0 {
+		t.IdleConnTimeout = idleConnTimeout
+	}
+
+	for _, opt := range options {
+		err := opt(t)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	c := *client
+	c.Transport = t
+
+	return &c, nil
+}
+
+func tlsConfig(t *http.Transport) *tls.Config {
+	if t.TLSClientConfig == nil {
+		t.TLSClientConfig = &tls.Config{}
+	}
+	return t.TLSClientConfig
+}
@@ -0,0 +1,256 @@
+package http_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/ysmood/kit"
+)
+
+func tlsServer(clientAuth bool) *httptest.Server {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		out := "ok"
+		if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+			out = r.TLS.PeerCertificates[0].Subject.CommonName
+		}
+		_, _ = w.Write([]byte(out))
+	}))
+	if clientAuth {
+		srv.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	}
+	srv.StartTLS()
+	return srv
+}
+
+func serverCA(srv *httptest.Server) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+}
+
+// generates a self-signed client certificate files
+func clientCert(dir string) (certFile, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	kit.E(err)
+
+	tpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	tpl.Subject.CommonName = "client"
+
+	der, err := x509.CreateCertificate(rand.Reader, tpl, tpl, &key.PublicKey, key)
+	kit.E(err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	kit.E(err)
+
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	kit.E(kit.OutputFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), nil))
+	kit.E(kit.OutputFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), nil))
+
+	return
+}
+
+func (s *RequestSuite) TestCA() {
+	srv := tlsServer(false)
+	defer srv.Close()
+
+	s.Error(kit.Req(srv.URL).Do())
+	s.Equal("ok", kit.Req(srv.URL).CA(serverCA(srv)).MustString())
+	s.Equal(kit.ErrInvalidCA, kit.Req(srv.URL).CA([]byte("x")).Do())
+}
+
+func (s *RequestSuite) TestCASharedPool() {
+	srv := tlsServer(false)
+	defer srv.Close()
+
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{RootCAs: x509.NewCertPool()},
+	}}
+
+	s.Equal("ok", kit.Req(srv.URL).Client(client).CA(serverCA(srv)).MustString())
+	s.Error(kit.Req(srv.URL).Client(client).Do())
+}
+
+func (s *RequestSuite) TestClientCertSharedConfig() {
+	srv := tlsServer(true)
+	defer srv.Close()
+
+	dir := filepath.Join("tmp", kit.RandString(8))
+	defer func() { _ = kit.Remove("tmp") }()
+	cert, key := clientCert(dir)
+
+	conf := &tls.Config{Certificates: make([]tls.Certificate, 0, 1)}
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: conf}}
+
+	s.Equal("client", kit.Req(srv.URL).Client(client).Insecure().ClientCert(cert, key).MustString())
+	s.Empty(conf.Certificates[:1][0].Certificate)
+}
+
+func (s *RequestSuite) TestSessionTransport() {
+	srv := tlsServer(true)
+	defer srv.Close()
+
+	dir := filepath.Join("tmp", kit.RandString(8))
+	defer func() { _ = kit.Remove("tmp") }()
+	cert, key := clientCert(dir)
+
+	session := kit.Session().CA(serverCA(srv)).ClientCert(cert, key)
+	s.Equal("client", session.Req(srv.URL).MustString())
+
+	// the connection pool of the session is reused
+	req := session.Req(srv.URL).Trace(false)
+	s.Equal("client", req.MustString())
+	s.True(req.Timing().Reused)
+
+	plain := tlsServer(false)
+	defer plain.Close()
+	s.Equal("ok", kit.Session().Insecure().Req(plain.URL).MustString())
+}
+
+func (s *RequestSuite) TestInsecure() {
+	srv := tlsServer(false)
+	defer srv.Close()
+
+	s.Equal("ok", kit.Req(srv.URL).Insecure().MustString())
+}
+
+func (s *RequestSuite) TestClientCert() {
+	srv := tlsServer(true)
+	defer srv.Close()
+
+	dir := filepath.Join("tmp", kit.RandString(8))
+	defer func() { _ = kit.Remove("tmp") }()
+	cert, key := clientCert(dir)
+
+	s.Equal("client", kit.Req(srv.URL).CA(serverCA(srv)).ClientCert(cert, key).MustString())
+	s.Error(kit.Req(srv.URL).ClientCert("not-exists", key).Do())
+}
+
+func (s *RequestSuite) TestUnixSocket() {
+	dir := filepath.Join("tmp", kit.RandString(8))
+	defer func() { _ = kit.Remove("tmp") }()
+	kit.E(kit.Mkdir(dir, nil))
+
+	sock := filepath.Join(dir, "s.sock")
+	ln, err := net.Listen("unix", sock)
+	kit.E(err)
+	defer func() { _ = ln.Close() }()
+
+	go func() {
+		_ = http.Serve(ln, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(r.Host + r.URL.Path))
+		}))
+	}()
+
+	s.Equal("docker/version", kit.Req("http://docker/version").UnixSocket(sock).MustString())
+}
+
+func (s *RequestSuite) TestSocks5Proxy() {
+	path, url := s.path()
+
+	s.router.GET(path, func(c kit.GinContext) {
+		c.String(200, "ok")
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	kit.E(err)
+	defer func() { _ = ln.Close() }()
+	go socks5(ln)
+
+	c := kit.Req(url).Proxy("socks5://" + ln.Addr().String())
+	s.Equal("ok", c.MustString())
+}
+
+func (s *RequestSuite) TestTransportCompose() {
+	srv := tlsServer(false)
+	defer srv.Close()
+
+	dir := filepath.Join("tmp", kit.RandString(8))
+	defer func() { _ = kit.Remove("tmp") }()
+	kit.E(kit.Mkdir(dir, nil))
+
+	sock := filepath.Join(dir, "s.sock")
+	ln, err := net.Listen("unix", sock)
+	kit.E(err)
+	defer func() { _ = ln.Close() }()
+	go func() { _ = http.Serve(tls.NewListener(ln, srv.TLS), srv.Config.Handler) }()
+
+	// both the tls option and the dialer option take effect on the same transport
+	s.Equal("ok", kit.Req("https://test.com").Insecure().UnixSocket(sock).MustString())
+	s.Error(kit.Req("https://test.com").UnixSocket(sock).Do())
+
+	c := &http.Client{Transport: roundTripper(func(*http.Request) (*http.Response, error) {
+		return nil, io.EOF
+	})}
+	s.Equal(kit.ErrNotTransport, kit.Req(srv.URL).Client(c).Insecure().Do())
+}
+
+type roundTripper func(*http.Request) (*http.Response, error)
+
+func (fn roundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	return fn(r)
+}
+
+// a minimal socks5 server that only supports the no-auth connect command
+func socks5(ln net.Listener) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+
+		go func() {
+			defer func() { _ = conn.Close() }()
+
+			head := make([]byte, 2)
+			_, _ = io.ReadFull(conn, head)
+			_, _ = io.ReadFull(conn, make([]byte, head[1]))
+			_, _ = conn.Write([]byte{5, 0})
+
+			req := make([]byte, 4)
+			_, _ = io.ReadFull(conn, req)
+
+			var host string
+			switch req[3] {
+			case 1:
+				ip := make([]byte, 4)
+				_, _ = io.ReadFull(conn, ip)
+				host = net.IP(ip).String()
+			case 3:
+				l := make([]byte, 1)
+				_, _ = io.ReadFull(conn, l)
+				name := make([]byte, l[0])
+				_, _ = io.ReadFull(conn, name)
+				host = string(name)
+			}
+			port := make([]byte, 2)
+			_, _ = io.ReadFull(conn, port)
+
+			target, err := net.Dial("tcp", net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port)))))
+			if err != nil {
+				return
+			}
+			defer func() { _ = target.Close() }()
+
+			_, _ = conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+
+			go func() { _, _ = io.Copy(target, conn) }()
+			_, _ = io.Copy(conn, target)
+		}()
+	}
+}
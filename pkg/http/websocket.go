@@ -0,0 +1,215 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/ysmood/kit/pkg/utils"
+)
+
+// WebSocket connection, it's safe to send and receive concurrently
+type WebSocket struct {
+	conn      *websocket.Conn
+	writeLock sync.Mutex
+	closeOnce sync.Once
+	closeErr  error
+
+	ctx    context.Context
+	cancel func()
+}
+
+// WebSocket dials the url as websocket, the http and https schemes will be converted to ws and wss.
+// The header, host, cookie jar, transport options and Context of the request will be used, the Timeout
+// of the request is the timeout of the handshake. The connection will be closed when the Context is done.
+func (ctx *ReqContext) WebSocket() (*WebSocket, error) {
+	if ctx.err != nil {
+		return nil, ctx.err
+	}
+
+	client := ctx.client
+	if client == nil {
+		client = newClient()
+	}
+
+	client, err := withTransport(client, ctx.transportOptions)
+	if err != nil {
+		return nil, err
+	}
+	t := client.Transport.(*http.Transport)
+
+	// websocket can only be upgraded from http/1.1
+	conf := tlsConfig(t).Clone()
+	conf.NextProtos = []string{"http/1.1"}
+
+	dialer := &websocket.Dialer{
+		Proxy:            t.Proxy,
+		TLSClientConfig:  conf,
+		NetDialContext:   t.DialContext,
+		HandshakeTimeout: ctx.timeout,
+		Jar:              client.Jar,
+	}
+
+	header := ctx.header.Clone()
+	if ctx.host != "" {
+		header.Set("Host", ctx.host)
+	}
+
+	c := ctx.context
+	if c == nil {
+		c = context.Background()
+	}
+
+	u := ctx.url
+	if strings.HasPrefix(u, "http") {
+		u = "ws" + strings.TrimPrefix(u, "http")
+	}
+
+	conn, res, err := dialer.DialContext(c, u, header)
+	if err != nil {
+		return nil, err
+	}
+	ctx.response = res
+
+	return newWebSocket(c, conn), nil
+}
+
+// MustWebSocket panic version of WebSocket
+func (ctx *ReqContext) MustWebSocket() *WebSocket {
+	return utils.E(ctx.WebSocket())[0].(*WebSocket)
+}
+
+// WebSocket upgrades the GET requests of the path to websocket, the connection will be closed
+// after the handler returns or the server is shutdown
+func (ctx *ServerContext) WebSocket(path string, handler func(c GinContext, ws *WebSocket)) *ServerContext {
+	upgrader := &websocket.Upgrader{}
+
+	ctx.Engine.GET(path, func(c GinContext) {
+		conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+		if err != nil {
+			return
+		}
+
+		ws := newWebSocket(context.Background(), conn)
+		defer func() { _ = ws.Close() }()
+
+		go func() {
+			select {
+			case <-ctx.closing:
+				_ = ws.Close()
+			case <-ws.ctx.Done():
+			}
+		}()
+
+		handler(c, ws)
+	})
+
+	return ctx
+}
+
+func newWebSocket(c context.Context, conn *websocket.Conn) *WebSocket {
+	c, cancel := context.WithCancel(c)
+	ws := &WebSocket{conn: conn, ctx: c, cancel: cancel}
+
+	go func() {
+		<-c.Done()
+		_ = ws.Close()
+	}()
+
+	return ws
+}
+
+// Context is done when the connection is closed
+func (ws *WebSocket) Context() context.Context {
+	return ws.ctx
+}
+
+// Send sends a text message
+func (ws *WebSocket) Send(msg []byte) error {
+	return ws.write(websocket.TextMessage, msg)
+}
+
+// SendJSON sends the v as a json text message
+func (ws *WebSocket) SendJSON(v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return ws.Send(b)
+}
+
+// Receive reads the next text or binary message, the ping and pong messages are handled automatically
+func (ws *WebSocket) Receive() ([]byte, error) {
+	_, msg, err := ws.conn.ReadMessage()
+	if err != nil {
+		ws.cancel()
+	}
+	return msg, err
+}
+
+// ReceiveJSON reads the next message and decodes it into the v
+func (ws *WebSocket) ReceiveJSON(v interface{}) error {
+	msg, err := ws.Receive()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(msg, v)
+}
+
+// Keepalive sends ping every interval, if no pong or message is received within two intervals,
+// the connection will be closed. Receive or ReceiveJSON needs to be called to handle the pongs.
+func (ws *WebSocket) Keepalive(interval time.Duration) *WebSocket {
+	extend := func() { _ = ws.conn.SetReadDeadline(time.Now().Add(2 * interval)) }
+	extend()
+
+	ws.conn.SetPongHandler(func(string) error {
+		extend()
+		return nil
+	})
+
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+
+		for {
+			select {
+			case <-t.C:
+				err := ws.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(interval))
+				if err != nil {
+					ws.cancel()
+					return
+				}
+			case <-ws.ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ws
+}
+
+// Close sends the close message and closes the connection
+func (ws *WebSocket) Close() error {
+	ws.closeOnce.Do(func() {
+		ws.cancel()
+
+		_ = ws.conn.WriteControl(
+			websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+			time.Now().Add(time.Second),
+		)
+		ws.closeErr = ws.conn.Close()
+	})
+	return ws.closeErr
+}
+
+func (ws *WebSocket) write(messageType int, data []byte) error {
+	ws.writeLock.Lock()
+	defer ws.writeLock.Unlock()
+
+	return ws.conn.WriteMessage(messageType, data)
+}
@@ -0,0 +1,136 @@
+package http_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/ysmood/kit"
+)
+
+func (s *RequestSuite) TestWebSocket() {
+	server, url := s.server()
+	server.WebSocket("/ws", func(_ kit.GinContext, ws *kit.WebSocket) {
+		for {
+			var msg map[string]interface{}
+			if ws.ReceiveJSON(&msg) != nil {
+				return
+			}
+			msg["echo"] = true
+			kit.E(ws.SendJSON(msg))
+		}
+	})
+
+	ws := kit.Req(url + "/ws").Timeout(time.Second).MustWebSocket()
+	defer func() { _ = ws.Close() }()
+
+	s.Nil(ws.SendJSON(map[string]int{"a": 1}))
+	var res map[string]interface{}
+	s.Nil(ws.ReceiveJSON(&res))
+	s.Equal(map[string]interface{}{"a": float64(1), "echo": true}, res)
+
+	s.Nil(ws.Send([]byte("not json")))
+	s.Error(ws.ReceiveJSON(&res))
+}
+
+func (s *RequestSuite) TestWebSocketHeader() {
+	server, url := s.server()
+	server.Engine.GET("/cookie", func(c kit.GinContext) {
+		c.SetCookie("id", "1", 0, "", "", false, false)
+	})
+	server.WebSocket("/ws/:name", func(c kit.GinContext, ws *kit.WebSocket) {
+		id, _ := c.Cookie("id")
+		_ = ws.Send([]byte(c.Param("name") + " " + c.GetHeader("X-Test") + " " + id))
+	})
+
+	session := kit.Session()
+	session.Req(url + "/cookie").MustDo()
+
+	ws := session.Req(url+"/ws/a").Header("X-Test", "ok").MustWebSocket()
+	defer func() { _ = ws.Close() }()
+
+	msg, err := ws.Receive()
+	s.Nil(err)
+	s.Equal("a ok 1", string(msg))
+}
+
+func (s *RequestSuite) TestWebSocketContext() {
+	server, url := s.server()
+
+	closed := make(chan error)
+	server.WebSocket("/ws", func(_ kit.GinContext, ws *kit.WebSocket) {
+		_, err := ws.Receive()
+		closed <- err
+	})
+
+	c, cancel := context.WithCancel(context.Background())
+	ws := kit.Req(url + "/ws").Context(c).MustWebSocket()
+
+	cancel()
+	<-ws.Context().Done()
+
+	s.Regexp(`close 1000`, (<-closed).Error())
+	s.Nil(ws.Close())
+}
+
+func (s *RequestSuite) TestWebSocketKeepalive() {
+	server, url := s.server()
+
+	result := make(chan error)
+	server.WebSocket("/ws", func(_ kit.GinContext, ws *kit.WebSocket) {
+		ws.Keepalive(20 * time.Millisecond)
+		_, err := ws.Receive()
+		result <- err
+	})
+
+	// the client handles the pings while reading
+	ws := kit.Req(url + "/ws").MustWebSocket()
+	go func() { _, _ = ws.Receive() }()
+	time.Sleep(100 * time.Millisecond)
+	s.Nil(ws.Send([]byte("ok")))
+	s.Nil(<-result)
+	_ = ws.Close()
+
+	// the client never reads, so no pong will be sent
+	ws = kit.Req(url + "/ws").MustWebSocket()
+	defer func() { _ = ws.Close() }()
+	s.Regexp(`timeout`, (<-result).Error())
+}
+
+func (s *RequestSuite) TestWebSocketTLS() {
+	server := kit.MustServer("127.0.0.1:0").SelfSigned()
+	server.WebSocket("/", func(_ kit.GinContext, ws *kit.WebSocket) {
+		_ = ws.Send([]byte("ok"))
+	})
+	go server.MustDo()
+	<-server.Ready()
+
+	url := "https://" + server.Listener.Addr().String()
+
+	_, err := kit.Req(url).WebSocket()
+	s.Error(err)
+
+	ws := kit.Req(url).CA(server.CA()).MustWebSocket()
+	defer func() { _ = ws.Close() }()
+	msg, err := ws.Receive()
+	s.Nil(err)
+	s.Equal("ok", string(msg))
+}
+
+func (s *RequestSuite) TestWebSocketShutdown() {
+	server, url := s.server()
+	server.WebSocket("/ws", func(_ kit.GinContext, ws *kit.WebSocket) {
+		<-ws.Context().Done()
+	})
+
+	ws := kit.Req(url + "/ws").MustWebSocket()
+
+	c, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	s.Nil(server.Shutdown(c))
+
+	_, err := ws.Receive()
+	s.Error(err)
+
+	_, err = kit.Req(url + "/ws").CA([]byte("x")).WebSocket()
+	s.Equal(kit.ErrInvalidCA, err)
+}
@@ -1,6 +1,7 @@
 package os
 
 import (
+	"context"
 	"os"
 	"os/signal"
 	"time"
@@ -8,14 +9,24 @@ import (
 
 // WaitSignal block until get specified os signals
 func WaitSignal(signals ...os.Signal) {
+	WaitSignalContext(context.Background(), signals...)
+}
+
+// WaitSignalContext is the same as WaitSignal, but it returns false if the ctx is done before any signal
+func WaitSignalContext(ctx context.Context, signals ...os.Signal) bool {
 	c := make(chan os.Signal, 1)
 	if len(signals) == 0 {
 		signals = append(signals, os.Interrupt)
 	}
 	signal.Notify(c, signals...)
-	<-c
-	signal.Stop(c)
-	close(c)
+	defer signal.Stop(c)
+
+	select {
+	case <-c:
+		return true
+	case <-ctx.Done():
+		return false
+	}
 }
 
 // RetryPanic retry function after a duration for several times, if success returns nil
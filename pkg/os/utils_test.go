@@ -1,6 +1,7 @@
 package os_test
 
 import (
+	"context"
 	"os"
 	"runtime"
 	"testing"
@@ -80,3 +81,10 @@ func TestEscape(t *T) {
 
 	assert.Equal(t, expected, kit.Escape("/?*"))
 }
+
+func TestWaitSignalContext(t *T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+
+	assert.False(t, kit.WaitSignalContext(ctx))
+}